@@ -0,0 +1,79 @@
+// Package agent 封装应用管理接口，包括应用详情、应用设置及自定义菜单
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Doer 由wecom.New返回的客户端实现
+type Doer interface {
+	Do(ctx context.Context, method, path string, query url.Values, body, out any) error
+}
+
+type Client struct {
+	d Doer
+}
+
+func New(d Doer) *Client {
+	return &Client{d: d}
+}
+
+type Agent struct {
+	AgentID       int    `json:"agentid"`
+	Name          string `json:"name"`
+	SquareLogoURL string `json:"square_logo_url"`
+	Description   string `json:"description"`
+	// 应用可见范围
+	AllowUserinfos struct {
+		User []struct {
+			Userid string `json:"userid"`
+		} `json:"user"`
+	} `json:"allow_userinfos"`
+	AllowPartys struct {
+		Partyid []int `json:"partyid"`
+	} `json:"allow_partys"`
+	AllowTags struct {
+		Tagid []int `json:"tagid"`
+	} `json:"allow_tags"`
+	// 1表示已禁用
+	Close              int    `json:"close"`
+	RedirectDomain     string `json:"redirect_domain"`
+	ReportLocationFlag int    `json:"report_location_flag"`
+	IsReportenter      int    `json:"isreportenter"`
+	HomeURL            string `json:"home_url"`
+}
+
+// 获取应用详情及可见范围
+func (c *Client) Get(ctx context.Context, agentID int) (*Agent, error) {
+	a := &Agent{}
+	if err := c.d.Do(ctx, http.MethodGet, "agent/get", agentQuery(agentID), nil, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// 以下字段为空时不修改
+type SetInfo struct {
+	AgentID        int    `json:"agentid"`
+	Name           string `json:"name,omitempty"`
+	LogoMediaID    string `json:"logo_mediaid,omitempty"`
+	Description    string `json:"description,omitempty"`
+	RedirectDomain string `json:"redirect_domain,omitempty"`
+	// 0不上报，1进入会话上报
+	ReportLocationFlag *int `json:"report_location_flag,omitempty"`
+	// 0不接收，1接收用户进入应用事件
+	IsReportenter *int   `json:"isreportenter,omitempty"`
+	HomeURL       string `json:"home_url,omitempty"`
+}
+
+// 设置应用
+func (c *Client) Set(ctx context.Context, s *SetInfo) error {
+	return c.d.Do(ctx, http.MethodPost, "agent/set", nil, s, nil)
+}
+
+func agentQuery(agentID int) url.Values {
+	return url.Values{"agentid": {strconv.Itoa(agentID)}}
+}
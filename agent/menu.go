@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+)
+
+// 一级菜单最多3个，每个一级菜单下最多5个子菜单
+type Button struct {
+	// click、view、scancode_push、scancode_waitmsg、pic_sysphoto、pic_photo_or_album、pic_weixin、location_select、view_miniprogram
+	Type      string   `json:"type,omitempty"`
+	Name      string   `json:"name"`
+	Key       string   `json:"key,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Pagepath  string   `json:"pagepath,omitempty"`
+	Appid     string   `json:"appid,omitempty"`
+	SubButton []Button `json:"sub_button,omitempty"`
+}
+
+// 创建菜单，会覆盖应用已有的菜单
+func (c *Client) CreateMenu(ctx context.Context, agentID int, buttons []Button) error {
+	return c.d.Do(ctx, http.MethodPost, "menu/create", agentQuery(agentID), map[string]any{
+		"button": buttons,
+	}, nil)
+}
+
+func (c *Client) GetMenu(ctx context.Context, agentID int) ([]Button, error) {
+	r := struct {
+		Button []Button `json:"button"`
+	}{}
+	if err := c.d.Do(ctx, http.MethodGet, "menu/get", agentQuery(agentID), nil, &r); err != nil {
+		return nil, err
+	}
+	return r.Button, nil
+}
+
+func (c *Client) DeleteMenu(ctx context.Context, agentID int) error {
+	return c.d.Do(ctx, http.MethodGet, "menu/delete", agentQuery(agentID), nil, nil)
+}
@@ -0,0 +1,262 @@
+// Package alertmanager 提供接收Prometheus Alertmanager webhook的http.Handler，按标签将告警推送给不同的接收者
+package alertmanager
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jzksnsjswkw/wecom-push"
+	"github.com/jzksnsjswkw/wecom-push/internal/route"
+)
+
+// Alertmanager webhook的请求体
+type Message struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []Alert           `json:"alerts"`
+}
+
+type Alert struct {
+	// firing或resolved
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Sender 由wecom.New返回的客户端实现
+type Sender interface {
+	Markdown(ctx context.Context, m *wecom.MarkdownInfo) (*wecom.SendResult, error)
+	TemplateCard(ctx context.Context, t *wecom.TemplateCardInfo) (*wecom.SendResult, error)
+}
+
+// Router 返回告警的接收者，ok为false时不推送该告警
+type Router func(a *Alert) (r wecom.Recipients, ok bool)
+
+// 根据标签label的值选择接收者，没有对应的值时使用fallback，fallback为零值时不推送
+func LabelRouter(label string, routes map[string]wecom.Recipients, fallback wecom.Recipients) Router {
+	return func(a *Alert) (wecom.Recipients, bool) {
+		return route.ByLabel(a.Labels, label, routes, fallback)
+	}
+}
+
+type Format int
+
+const (
+	// 同一接收者的告警合并为一条markdown消息
+	FormatMarkdown Format = iota
+	// 每条告警一张文本通知型模板卡片
+	FormatTemplateCard
+)
+
+// 单条markdown消息最多包含的告警数，超出部分只注明数量
+const maxAlertsPerMessage = 10
+
+type Handler struct {
+	s      Sender
+	router Router
+	format Format
+	token  string
+}
+
+func NewHandler(s Sender, router Router, format Format) *Handler {
+	return &Handler{s: s, router: router, format: format}
+}
+
+// 请求需携带Authorization: Bearer <token>，对应Alertmanager webhook_configs的http_config.authorization，为空时不校验
+func (h *Handler) SetToken(token string) {
+	h.token = token
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if h.token != "" {
+		got, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+			http.Error(rw, "invalid token", http.StatusUnauthorized)
+			return
+		}
+	}
+	m := &Message{}
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, 1<<20)).Decode(m); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.Notify(r.Context(), m); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadGateway)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// 按Router分组推送m中的告警
+func (h *Handler) Notify(ctx context.Context, m *Message) error {
+	groups := map[wecom.Recipients][]*Alert{}
+	var order []wecom.Recipients
+	for i := range m.Alerts {
+		a := &m.Alerts[i]
+		rcpt, ok := h.router(a)
+		if !ok {
+			continue
+		}
+		if _, ok := groups[rcpt]; !ok {
+			order = append(order, rcpt)
+		}
+		groups[rcpt] = append(groups[rcpt], a)
+	}
+
+	var errs []error
+	for _, rcpt := range order {
+		if h.format == FormatTemplateCard {
+			for _, a := range groups[rcpt] {
+				if err := h.sendCard(ctx, rcpt, m, a); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+		if err := h.sendMarkdown(ctx, rcpt, groups[rcpt]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *Handler) sendMarkdown(ctx context.Context, rcpt wecom.Recipients, alerts []*Alert) error {
+	_, err := h.s.Markdown(ctx, &wecom.MarkdownInfo{
+		Touser:  rcpt.Touser,
+		Toparty: rcpt.Toparty,
+		Totag:   rcpt.Totag,
+		AgentID: rcpt.AgentID,
+		Content: markdown(alerts),
+	})
+	return err
+}
+
+func title(a *Alert) string {
+	status := "FIRING"
+	if a.Status == "resolved" {
+		status = "RESOLVED"
+	}
+	return fmt.Sprintf("[%s] %s", status, a.Labels["alertname"])
+}
+
+func summary(a *Alert) string {
+	if s := a.Annotations["summary"]; s != "" {
+		return s
+	}
+	return a.Annotations["description"]
+}
+
+// 除alertname外的标签，按名称排序
+func labelKeys(a *Alert) []string {
+	keys := make([]string, 0, len(a.Labels))
+	for k := range a.Labels {
+		if k != "alertname" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// markdown消息内容最长4096字节
+const maxMarkdownBytes = 4096
+
+// 为“另有N条告警未显示”预留的长度
+const omittedReserve = 64
+
+func markdown(alerts []*Alert) string {
+	var b strings.Builder
+	for i, a := range alerts {
+		s := markdownAlert(a)
+		if i == 0 && len(s) > maxMarkdownBytes-omittedReserve {
+			s = wecom.TruncateToBytes(s, maxMarkdownBytes-omittedReserve-2) + "\n\n"
+		}
+		if i == maxAlertsPerMessage || b.Len()+len(s) > maxMarkdownBytes-omittedReserve {
+			fmt.Fprintf(&b, "另有%d条告警未显示\n", len(alerts)-i)
+			break
+		}
+		b.WriteString(s)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func markdownAlert(a *Alert) string {
+	var b strings.Builder
+	color := "warning"
+	if a.Status == "resolved" {
+		color = "info"
+	}
+	fmt.Fprintf(&b, "<font color=\"%s\">**%s**</font>\n", color, title(a))
+	if s := summary(a); s != "" {
+		fmt.Fprintf(&b, "%s\n", s)
+	}
+	for _, k := range labelKeys(a) {
+		fmt.Fprintf(&b, "> %s：%s\n", k, a.Labels[k])
+	}
+	fmt.Fprintf(&b, "> 开始：%s\n", a.StartsAt.Local().Format(time.DateTime))
+	if a.Status == "resolved" {
+		fmt.Fprintf(&b, "> 恢复：%s\n", a.EndsAt.Local().Format(time.DateTime))
+	}
+	if a.GeneratorURL != "" {
+		fmt.Fprintf(&b, "[查看详情](%s)\n", a.GeneratorURL)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (h *Handler) sendCard(ctx context.Context, rcpt wecom.Recipients, m *Message, a *Alert) error {
+	card := &wecom.TemplateCard{
+		CardType:  wecom.TextNotice,
+		Source:    &wecom.CardSource{Desc: "Alertmanager", DescColor: 2},
+		MainTitle: &wecom.CardMainTitle{Title: title(a), Desc: summary(a)},
+	}
+	if a.Status == "resolved" {
+		card.Source.DescColor = 3
+	}
+	for _, k := range labelKeys(a) {
+		// 最多6条
+		if len(card.HorizontalContentList) == 6 {
+			break
+		}
+		card.HorizontalContentList = append(card.HorizontalContentList, wecom.CardHorizontalContent{Keyname: k, Value: a.Labels[k]})
+	}
+	// 点击卡片跳转到告警来源，文本通知型卡片必须设置跳转，均未提供时改为推送markdown消息
+	u := a.GeneratorURL
+	if u == "" {
+		u = m.ExternalURL
+	}
+	if u == "" {
+		return h.sendMarkdown(ctx, rcpt, []*Alert{a})
+	}
+	card.CardAction = &wecom.CardAction{Type: 1, URL: u}
+	_, err := h.s.TemplateCard(ctx, &wecom.TemplateCardInfo{
+		Touser:  rcpt.Touser,
+		Toparty: rcpt.Toparty,
+		Totag:   rcpt.Totag,
+		AgentID: rcpt.AgentID,
+		Card:    card,
+	})
+	return err
+}
@@ -0,0 +1,135 @@
+package wecom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+)
+
+type AppChatCreateInfo struct {
+	// 群聊名，最多50个utf8字符
+	Name string
+	// 群主userid，为空时从Userlist中随机选一人
+	Owner string
+	// 群成员userid列表，至少2人，至多2000人
+	Userlist []string
+	// 群聊的唯一标志，为空时由系统自动生成
+	ChatID string
+}
+
+// 创建群聊会话，返回chatid
+func (w *Wecom) CreateAppChat(ctx context.Context, c *AppChatCreateInfo) (string, error) {
+	if len(c.Userlist) < 2 || len(c.Userlist) > 2000 {
+		return "", errors.New("appchat userlist count must be between 2 and 2000")
+	}
+	d := map[string]any{
+		"name":     c.Name,
+		"owner":    c.Owner,
+		"userlist": c.Userlist,
+	}
+	if c.ChatID != "" {
+		d["chatid"] = c.ChatID
+	}
+	b, err := w.post(ctx, "appchat/create", d)
+	if err != nil {
+		return "", err
+	}
+	r := struct {
+		ChatID string `json:"chatid"`
+	}{}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return "", err
+	}
+	return r.ChatID, nil
+}
+
+type AppChat struct {
+	ChatID   string   `json:"chatid"`
+	Name     string   `json:"name"`
+	Owner    string   `json:"owner"`
+	Userlist []string `json:"userlist"`
+	// 0普通群，1家校群
+	ChatType int `json:"chat_type"`
+}
+
+func (w *Wecom) GetAppChat(ctx context.Context, chatid string) (*AppChat, error) {
+	b, err := w.get(ctx, "appchat/get", url.Values{"chatid": {chatid}})
+	if err != nil {
+		return nil, err
+	}
+	r := struct {
+		ChatInfo *AppChat `json:"chat_info"`
+	}{}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+	return r.ChatInfo, nil
+}
+
+type AppChatUpdateInfo struct {
+	ChatID string
+	// 以下字段为空时不修改
+	Name        string
+	Owner       string
+	AddUserList []string
+	DelUserList []string
+}
+
+func (w *Wecom) UpdateAppChat(ctx context.Context, u *AppChatUpdateInfo) error {
+	d := map[string]any{
+		"chatid": u.ChatID,
+	}
+	if u.Name != "" {
+		d["name"] = u.Name
+	}
+	if u.Owner != "" {
+		d["owner"] = u.Owner
+	}
+	if len(u.AddUserList) > 0 {
+		d["add_user_list"] = u.AddUserList
+	}
+	if len(u.DelUserList) > 0 {
+		d["del_user_list"] = u.DelUserList
+	}
+	_, err := w.post(ctx, "appchat/update", d)
+	return err
+}
+
+// 应用只能向自己创建的群聊推送消息
+func (w *Wecom) sendAppChat(ctx context.Context, chatid, msgtype string, body any, safe bool) error {
+	d := map[string]any{
+		"chatid":  chatid,
+		"msgtype": msgtype,
+		msgtype:   body,
+	}
+	if safe {
+		d["safe"] = 1
+	}
+	_, err := w.post(withMsgtype(ctx, msgtype), "appchat/send", d)
+	w.observeMessage(msgtype, err)
+	return err
+}
+
+func (w *Wecom) AppChatText(ctx context.Context, chatid, content string, safe bool) error {
+	return w.sendAppChat(ctx, chatid, "text", map[string]string{
+		"content": content,
+	}, safe)
+}
+
+func (w *Wecom) AppChatMarkdown(ctx context.Context, chatid, content string) error {
+	return w.sendAppChat(ctx, chatid, "markdown", map[string]string{
+		"content": content,
+	}, false)
+}
+
+// 上传并发送文件
+func (w *Wecom) AppChatFile(ctx context.Context, chatid string, content []byte, filename string, safe bool) error {
+	m, err := w.getMediaID(ctx, content, FILE, filename)
+	if err != nil {
+		return err
+	}
+	return w.sendAppChat(ctx, chatid, "file", map[string]string{
+		"media_id": m,
+	}, safe)
+}
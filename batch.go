@@ -0,0 +1,82 @@
+package wecom
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// 分批发送的汇总结果
+type BatchResult struct {
+	// 各批次成功发送的msgid
+	MsgIDs         []string
+	InvalidUser    []string
+	InvalidParty   []string
+	InvalidTag     []string
+	UnlicensedUser []string
+}
+
+func (b *BatchResult) add(r *SendResult) {
+	b.MsgIDs = append(b.MsgIDs, r.MsgID)
+	b.InvalidUser = appendSplit(b.InvalidUser, r.InvalidUser)
+	b.InvalidParty = appendSplit(b.InvalidParty, r.InvalidParty)
+	b.InvalidTag = appendSplit(b.InvalidTag, r.InvalidTag)
+	b.UnlicensedUser = appendSplit(b.UnlicensedUser, r.UnlicensedUser)
+}
+
+func appendSplit(s []string, list string) []string {
+	if list == "" {
+		return s
+	}
+	return append(s, strings.Split(list, "|")...)
+}
+
+// 将userids按每批1000个拆分后调用send发送，send的touser参数为以“|”分隔的一批userid
+// parallel为同时发送的批次数，小于等于1时逐批发送；某一批次失败不影响其余批次，返回的错误包含所有失败批次的错误
+func (w *Wecom) BatchSend(ctx context.Context, userids []string, parallel int, send func(ctx context.Context, touser string) (*SendResult, error)) (*BatchResult, error) {
+	var chunks []string
+	for i := 0; i < len(userids); i += maxTouser {
+		chunks = append(chunks, strings.Join(userids[i:min(i+maxTouser, len(userids))], "|"))
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	var (
+		lock sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	b := &BatchResult{}
+	sem := make(chan struct{}, parallel)
+	for _, touser := range chunks {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(touser string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			r, err := send(ctx, touser)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			b.add(r)
+		}(touser)
+	}
+	wg.Wait()
+	return b, errors.Join(errs...)
+}
+
+// 向任意数量的成员发送文本消息，t.Touser被忽略
+func (w *Wecom) BatchText(ctx context.Context, userids []string, t *TextInfo, parallel int) (*BatchResult, error) {
+	return w.BatchSend(ctx, userids, parallel, func(ctx context.Context, touser string) (*SendResult, error) {
+		c := *t
+		c.Touser = touser
+		return w.Text(ctx, &c)
+	})
+}
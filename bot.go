@@ -0,0 +1,184 @@
+package wecom
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Bot 群机器人，通过webhook key发送消息，无需企业应用及access_token
+type Bot struct {
+	key string
+	w   *Wecom
+}
+
+// key为webhook地址中的key参数，opts中HTTP相关的配置（WithHTTPClient、WithBaseURL、WithProxy、WithTimeout、钩子及日志等）同样生效
+func NewBot(key string, opts ...Option) *Bot {
+	return &Bot{key: key, w: New("", "", opts...)}
+}
+
+func (b *Bot) send(ctx context.Context, msgtype string, body any) error {
+	d, err := json.Marshal(map[string]any{
+		"msgtype": msgtype,
+		msgtype:   body,
+	})
+	if err != nil {
+		return err
+	}
+	u := b.w.baseURL + "webhook/send?key=" + url.QueryEscape(b.key)
+	r, err := http.NewRequestWithContext(withMsgtype(ctx, msgtype), http.MethodPost, u, bytes.NewReader(d))
+	if err != nil {
+		return err
+	}
+	r.Header.Add("content-type", "application/json")
+	r.Header.Add("accept", "application/json")
+	resp, err := b.w.do(r)
+	if err == nil {
+		if errcode, errmsg, ok := errcodeOf(resp); ok && errcode != 0 {
+			err = &Error{Errcode: errcode, Errmsg: errmsg}
+		}
+	}
+	b.w.observeMessage(msgtype, err)
+	return err
+}
+
+// 提醒群中所有人
+const MentionAll = "@all"
+
+type BotTextInfo struct {
+	// 最长不超过2048个字节
+	Content string
+	// 需要@的成员userid，可以为MentionAll
+	MentionedList []string
+	// 需要@的成员手机号，可以为MentionAll
+	MentionedMobileList []string
+}
+
+func (b *Bot) Text(ctx context.Context, t *BotTextInfo) error {
+	body := b.w.truncateContent("text", map[string]string{"content": t.Content})
+	content := body.(map[string]string)["content"]
+	if err := validateContent("text content", content, maxTextBytes); err != nil {
+		return err
+	}
+	d := map[string]any{
+		"content": content,
+	}
+	if len(t.MentionedList) > 0 {
+		d["mentioned_list"] = t.MentionedList
+	}
+	if len(t.MentionedMobileList) > 0 {
+		d["mentioned_mobile_list"] = t.MentionedMobileList
+	}
+	return b.send(ctx, "text", d)
+}
+
+// content最长不超过4096个字节
+func (b *Bot) Markdown(ctx context.Context, content string) error {
+	body := b.w.truncateContent("markdown", map[string]string{"content": content})
+	if err := validateContent("markdown content", body.(map[string]string)["content"], maxMarkdownBytes); err != nil {
+		return err
+	}
+	return b.send(ctx, "markdown", body)
+}
+
+// 图片最大不超过2MB，支持JPG、PNG格式
+func (b *Bot) Image(ctx context.Context, content []byte) error {
+	if len(content) > 2<<20 {
+		return errors.New("bot image size must not exceed 2MB")
+	}
+	sum := md5.Sum(content)
+	return b.send(ctx, "image", map[string]string{
+		"base64": base64.StdEncoding.EncodeToString(content),
+		"md5":    hex.EncodeToString(sum[:]),
+	})
+}
+
+// 1~8条
+func (b *Bot) News(ctx context.Context, articles []Article) error {
+	if len(articles) == 0 || len(articles) > 8 {
+		return errors.New("news articles count must be between 1 and 8")
+	}
+	return b.send(ctx, "news", map[string]any{
+		"articles": articles,
+	})
+}
+
+// 上传文件到群机器人，返回的media_id仅对当前key有效，3天内有效
+// filetype仅支持FILE（不超过20MB）和VOICE（不超过2MB，AMR格式，不超过60s）
+func (b *Bot) UploadMedia(ctx context.Context, content []byte, filetype Filetype, filename string) (string, error) {
+	if filetype != FILE && filetype != VOICE {
+		return "", errors.New("bot only supports uploading file and voice")
+	}
+	if err := validateMedia(filetype, int64(len(content)), content); err != nil {
+		return "", err
+	}
+	if filetype == VOICE {
+		d, err := amrDuration(content)
+		if err != nil {
+			return "", err
+		}
+		if d > 60*time.Second {
+			return "", errors.New("voice duration must not exceed 60s")
+		}
+	}
+
+	if b.w.dryRun {
+		b.w.logDryRun("webhook/upload_media type=%s filename=%s size=%d", filetype, filename, len(content))
+		return dryRunMediaID, nil
+	}
+	u := fmt.Sprintf("%vwebhook/upload_media?key=%v&type=%v", b.w.baseURL, url.QueryEscape(b.key), filetype)
+	resp, err := b.w.upload(ctx, u, content, filename)
+	if err != nil {
+		return "", err
+	}
+	r := struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+		MediaID string `json:"media_id"`
+	}{}
+	if err := json.Unmarshal(resp, &r); err != nil {
+		return "", err
+	}
+	if r.ErrCode != 0 {
+		return "", &Error{Errcode: r.ErrCode, Errmsg: r.ErrMsg}
+	}
+	return r.MediaID, nil
+}
+
+// 上传并发送文件
+func (b *Bot) File(ctx context.Context, content []byte, filename string) error {
+	m, err := b.UploadMedia(ctx, content, FILE, filename)
+	if err != nil {
+		return err
+	}
+	return b.send(ctx, "file", map[string]string{
+		"media_id": m,
+	})
+}
+
+// 上传并发送语音，仅支持AMR格式
+func (b *Bot) Voice(ctx context.Context, content []byte) error {
+	m, err := b.UploadMedia(ctx, content, VOICE, "voice.amr")
+	if err != nil {
+		return err
+	}
+	return b.send(ctx, "voice", map[string]string{
+		"media_id": m,
+	})
+}
+
+// 仅支持text_notice和news_notice
+func (b *Bot) TemplateCard(ctx context.Context, card *TemplateCard) error {
+	if card == nil {
+		return errors.New("template card is nil")
+	}
+	return b.send(ctx, "template_card", card)
+}
@@ -0,0 +1,73 @@
+package wecom
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("wecom: circuit breaker is open")
+
+type breaker struct {
+	lock      sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	// 冷却结束后是否已放行一次试探请求
+	probing bool
+}
+
+// 连续threshold次网络错误或5xx响应后熔断，cooldown内的请求直接返回ErrCircuitOpen
+// 冷却结束后放行一次试探请求，成功则恢复，失败则重新熔断
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(w *Wecom) {
+		if threshold < 1 {
+			threshold = 1
+		}
+		w.breaker = &breaker{threshold: threshold, cooldown: cooldown}
+	}
+}
+
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+func (b *breaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.probing = false
+	if err == nil || !isTransient(err) {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// 试探请求被调用者取消时，允许下一个请求继续试探
+func (b *breaker) abort() {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.probing = false
+}
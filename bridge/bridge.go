@@ -0,0 +1,109 @@
+// Package bridge 提供HTTP推送服务，其他语言的服务或shell脚本可通过POST /push经由本库发送应用消息
+package bridge
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+// Sender 由wecom.New返回的客户端实现
+type Sender interface {
+	Text(ctx context.Context, t *wecom.TextInfo) (*wecom.SendResult, error)
+	Markdown(ctx context.Context, m *wecom.MarkdownInfo) (*wecom.SendResult, error)
+	TextCard(ctx context.Context, t *wecom.TextCardInfo) (*wecom.SendResult, error)
+}
+
+// POST /push的请求体
+type PushRequest struct {
+	// 成员userid，多个以“|”分隔
+	To      string `json:"to"`
+	Toparty string `json:"toparty,omitempty"`
+	Totag   string `json:"totag,omitempty"`
+	AgentID int    `json:"agentid,omitempty"`
+	// text、markdown或textcard，默认为text
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	// 以下仅textcard使用，Content作为卡片描述
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+type pushResponse struct {
+	MsgID       string `json:"msgid,omitempty"`
+	InvalidUser string `json:"invaliduser,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// 返回处理POST /push的http.Handler
+// 请求需携带Authorization: Bearer <token>，token为空时不校验，仅应在内网使用
+func NewServer(s Sender, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/push", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(rw, http.StatusMethodNotAllowed, &pushResponse{Error: "method not allowed"})
+			return
+		}
+		if token != "" {
+			got, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				writeJSON(rw, http.StatusUnauthorized, &pushResponse{Error: "invalid token"})
+				return
+			}
+		}
+		p := &PushRequest{}
+		if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, 1<<20)).Decode(p); err != nil {
+			writeJSON(rw, http.StatusBadRequest, &pushResponse{Error: err.Error()})
+			return
+		}
+		res, err := push(r.Context(), s, p)
+		if err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, errBadRequest) || errors.Is(err, wecom.ErrToAllNotAllowed) {
+				status = http.StatusBadRequest
+			}
+			writeJSON(rw, status, &pushResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(rw, http.StatusOK, &pushResponse{MsgID: res.MsgID, InvalidUser: res.InvalidUser})
+	})
+	return mux
+}
+
+var errBadRequest = errors.New("bridge: bad request")
+
+func push(ctx context.Context, s Sender, p *PushRequest) (*wecom.SendResult, error) {
+	if p.To == "" && p.Toparty == "" && p.Totag == "" {
+		return nil, fmt.Errorf("%w: to, toparty or totag is required", errBadRequest)
+	}
+	switch p.Type {
+	case "", "text":
+		return s.Text(ctx, &wecom.TextInfo{Touser: p.To, Toparty: p.Toparty, Totag: p.Totag, AgentID: p.AgentID, Content: p.Content})
+	case "markdown":
+		return s.Markdown(ctx, &wecom.MarkdownInfo{Touser: p.To, Toparty: p.Toparty, Totag: p.Totag, AgentID: p.AgentID, Content: p.Content})
+	case "textcard":
+		return s.TextCard(ctx, &wecom.TextCardInfo{
+			Touser:      p.To,
+			Toparty:     p.Toparty,
+			Totag:       p.Totag,
+			AgentID:     p.AgentID,
+			Title:       p.Title,
+			Description: p.Content,
+			URL:         p.URL,
+		})
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %q", errBadRequest, p.Type)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v any) {
+	rw.Header().Set("content-type", "application/json")
+	rw.WriteHeader(status)
+	json.NewEncoder(rw).Encode(v)
+}
@@ -0,0 +1,114 @@
+package wecom
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// MessageBuilder 以链式调用构造并发送应用消息，如
+//
+//	w.NewMessage().ToUsers("a", "b").ToParty(3).Markdown("**down**").Send(ctx)
+type MessageBuilder struct {
+	w       *Wecom
+	m       message
+	msgtype string
+	body    any
+	// news的图文数，用于发送前校验
+	articles int
+}
+
+func (w *Wecom) NewMessage() *MessageBuilder {
+	return &MessageBuilder{w: w}
+}
+
+func appendList(list, s string) string {
+	if list == "" {
+		return s
+	}
+	return list + "|" + s
+}
+
+func (b *MessageBuilder) ToUsers(userids ...string) *MessageBuilder {
+	b.m.Touser = appendList(b.m.Touser, strings.Join(userids, "|"))
+	return b
+}
+
+func (b *MessageBuilder) ToParty(partyIDs ...int) *MessageBuilder {
+	for _, id := range partyIDs {
+		b.m.Toparty = appendList(b.m.Toparty, strconv.Itoa(id))
+	}
+	return b
+}
+
+func (b *MessageBuilder) ToTag(tagIDs ...int) *MessageBuilder {
+	for _, id := range tagIDs {
+		b.m.Totag = appendList(b.m.Totag, strconv.Itoa(id))
+	}
+	return b
+}
+
+// 为0时使用WithAgentID设置的默认值
+func (b *MessageBuilder) Agent(agentID int) *MessageBuilder {
+	b.m.AgentID = agentID
+	return b
+}
+
+func (b *MessageBuilder) Safe() *MessageBuilder {
+	b.m.Safe = true
+	return b
+}
+
+func (b *MessageBuilder) IDTrans() *MessageBuilder {
+	b.m.EnableIDTrans = true
+	return b
+}
+
+// interval为0时使用默认的1800秒
+func (b *MessageBuilder) DuplicateCheck(interval int) *MessageBuilder {
+	b.m.EnableDuplicateCheck = true
+	b.m.DuplicateCheckInterval = interval
+	return b
+}
+
+func (b *MessageBuilder) Text(content string) *MessageBuilder {
+	b.msgtype, b.body = "text", map[string]string{"content": content}
+	return b
+}
+
+func (b *MessageBuilder) Markdown(content string) *MessageBuilder {
+	b.msgtype, b.body = "markdown", map[string]string{"content": content}
+	return b
+}
+
+// btntxt为空时按钮文字为“详情”
+func (b *MessageBuilder) TextCard(title, description, url, btntxt string) *MessageBuilder {
+	card := map[string]string{
+		"title":       title,
+		"description": description,
+		"url":         url,
+	}
+	if btntxt != "" {
+		card["btntxt"] = btntxt
+	}
+	b.msgtype, b.body = "textcard", card
+	return b
+}
+
+func (b *MessageBuilder) News(articles ...Article) *MessageBuilder {
+	b.msgtype, b.body = "news", map[string]any{"articles": articles}
+	b.articles = len(articles)
+	return b
+}
+
+func (b *MessageBuilder) Send(ctx context.Context) (*SendResult, error) {
+	if b.msgtype == "" {
+		return nil, errors.New("message content is required")
+	}
+	if b.msgtype == "news" && (b.articles == 0 || b.articles > 8) {
+		return nil, errors.New("news articles count must be between 1 and 8")
+	}
+	m := b.m
+	return b.w.sendMessage(ctx, &m, b.msgtype, b.body)
+}
@@ -0,0 +1,198 @@
+// Package callback 实现企业微信回调的加解密，用于接收成员消息及事件
+package callback
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSignature  = errors.New("callback: invalid signature")
+	ErrInvalidReceiveID  = errors.New("callback: receive id mismatch")
+	ErrInvalidAESKey     = errors.New("callback: encoding aes key must be 43 characters")
+	ErrInvalidCiphertext = errors.New("callback: invalid ciphertext")
+)
+
+// Crypto 使用回调配置中的Token和EncodingAESKey校验签名并加解密消息
+type Crypto struct {
+	token     string
+	receiveID string
+	key       []byte
+}
+
+// receiveID在企业自建应用中为corpid，第三方应用中为suiteid
+func NewCrypto(token, encodingAESKey, receiveID string) (*Crypto, error) {
+	if len(encodingAESKey) != 43 {
+		return nil, ErrInvalidAESKey
+	}
+	key, err := base64.StdEncoding.DecodeString(encodingAESKey + "=")
+	if err != nil {
+		return nil, ErrInvalidAESKey
+	}
+	return &Crypto{token: token, receiveID: receiveID, key: key}, nil
+}
+
+// 将token、timestamp、nonce、encrypt按字典序排序后拼接并计算sha1
+func (c *Crypto) Signature(timestamp, nonce, encrypt string) string {
+	s := []string{c.token, timestamp, nonce, encrypt}
+	sort.Strings(s)
+	h := sha1.Sum([]byte(strings.Join(s, "")))
+	return hex.EncodeToString(h[:])
+}
+
+// 企业微信的PKCS#7填充以32字节为块大小
+const blockSize = 32
+
+func pkcs7Unpad(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, ErrInvalidCiphertext
+	}
+	n := int(b[len(b)-1])
+	if n < 1 || n > blockSize || n > len(b) {
+		return nil, ErrInvalidCiphertext
+	}
+	return b[:len(b)-n], nil
+}
+
+// 明文格式为random(16B) + msg_len(4B) + msg + receiveid
+func (c *Crypto) decrypt(encrypt string) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypt)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, ErrInvalidCiphertext
+	}
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, c.key[:aes.BlockSize]).CryptBlocks(plain, ciphertext)
+	plain, err = pkcs7Unpad(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plain) < 20 {
+		return nil, ErrInvalidCiphertext
+	}
+	n := int(binary.BigEndian.Uint32(plain[16:20]))
+	if n > len(plain)-20 {
+		return nil, ErrInvalidCiphertext
+	}
+	msg, receiveID := plain[20:20+n], plain[20+n:]
+	if !bytes.Equal(receiveID, []byte(c.receiveID)) {
+		return nil, ErrInvalidReceiveID
+	}
+	return msg, nil
+}
+
+// 以常数时间比较签名
+func (c *Crypto) verify(msgSignature, timestamp, nonce, encrypt string) bool {
+	return subtle.ConstantTimeCompare([]byte(c.Signature(timestamp, nonce, encrypt)), []byte(msgSignature)) == 1
+}
+
+// 校验回调URL，返回需原样响应的echostr明文，参数需先进行URL解码
+func (c *Crypto) VerifyURL(msgSignature, timestamp, nonce, echostr string) (string, error) {
+	if !c.verify(msgSignature, timestamp, nonce, echostr) {
+		return "", ErrInvalidSignature
+	}
+	b, err := c.decrypt(echostr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+type encryptedBody struct {
+	ToUserName string `xml:"ToUserName"`
+	AgentID    string `xml:"AgentID"`
+	Encrypt    string `xml:"Encrypt"`
+}
+
+// 校验签名并解密POST请求体，返回消息明文XML
+func (c *Crypto) DecryptMessage(msgSignature, timestamp, nonce string, body []byte) ([]byte, error) {
+	e := &encryptedBody{}
+	if err := xml.Unmarshal(body, e); err != nil {
+		return nil, err
+	}
+	if !c.verify(msgSignature, timestamp, nonce, e.Encrypt) {
+		return nil, ErrInvalidSignature
+	}
+	return c.decrypt(e.Encrypt)
+}
+
+func pkcs7Pad(b []byte) []byte {
+	n := blockSize - len(b)%blockSize
+	return append(b, bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+func (c *Crypto) encrypt(msg []byte) (string, error) {
+	plain := make([]byte, 20, 20+len(msg)+len(c.receiveID)+blockSize)
+	if _, err := rand.Read(plain[:16]); err != nil {
+		return "", err
+	}
+	binary.BigEndian.PutUint32(plain[16:20], uint32(len(msg)))
+	plain = append(plain, msg...)
+	plain = append(plain, c.receiveID...)
+	plain = pkcs7Pad(plain)
+
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return "", err
+	}
+	ciphertext := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, c.key[:aes.BlockSize]).CryptBlocks(ciphertext, plain)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+type encryptedReply struct {
+	XMLName      xml.Name `xml:"xml"`
+	Encrypt      cdata    `xml:"Encrypt"`
+	MsgSignature cdata    `xml:"MsgSignature"`
+	TimeStamp    string   `xml:"TimeStamp"`
+	Nonce        cdata    `xml:"Nonce"`
+}
+
+// 加密被动回复的消息，返回可直接作为响应体的XML
+// timestamp为空时使用当前时间，nonce为空时随机生成
+func (c *Crypto) EncryptMessage(reply []byte, timestamp, nonce string) ([]byte, error) {
+	if timestamp == "" {
+		timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+	}
+	if nonce == "" {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		nonce = hex.EncodeToString(b)
+	}
+	encrypt, err := c.encrypt(reply)
+	if err != nil {
+		return nil, err
+	}
+	return xml.Marshal(&encryptedReply{
+		Encrypt:      cdata{encrypt},
+		MsgSignature: cdata{c.Signature(timestamp, nonce, encrypt)},
+		TimeStamp:    timestamp,
+		Nonce:        cdata{nonce},
+	})
+}
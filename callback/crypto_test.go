@@ -0,0 +1,105 @@
+package callback
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	testToken     = "QDG6eK"
+	testAESKey    = "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C"
+	testReceiveID = "wx5823bf96d3bd56c7"
+)
+
+func newTestCrypto(t *testing.T) *Crypto {
+	t.Helper()
+	c, err := NewCrypto(testToken, testAESKey, testReceiveID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestNewCrypto(t *testing.T) {
+	tests := []struct {
+		key  string
+		want error
+	}{
+		{testAESKey, nil},
+		{testAESKey[:42], ErrInvalidAESKey},
+		{testAESKey[:42] + "!", ErrInvalidAESKey},
+	}
+	for _, tt := range tests {
+		if _, err := NewCrypto(testToken, tt.key, testReceiveID); !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+			t.Errorf("NewCrypto(%q) error = %v, want %v", tt.key, err, tt.want)
+		}
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	c := newTestCrypto(t)
+	tests := []string{
+		"<xml><Content><![CDATA[hello]]></Content></xml>",
+		"<xml><Content><![CDATA[中文消息]]></Content></xml>",
+		// 恰好为块大小的整数倍时补齐一整块
+		strings.Repeat("a", blockSize*3-20-len(testReceiveID)),
+		"",
+	}
+	for _, msg := range tests {
+		body, err := c.EncryptMessage([]byte(msg), "1409304348", "xxxxxx")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := &encryptedReply{}
+		if err := xml.Unmarshal(body, r); err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.DecryptMessage(r.MsgSignature.Value, r.TimeStamp, r.Nonce.Value, body)
+		if err != nil {
+			t.Fatalf("DecryptMessage(%q): %v", msg, err)
+		}
+		if string(got) != msg {
+			t.Errorf("DecryptMessage = %q, want %q", got, msg)
+		}
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	c := newTestCrypto(t)
+	other, err := NewCrypto(testToken, testAESKey, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypt, err := c.encrypt([]byte("echo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := c.Signature("1409304348", "nonce", encrypt)
+
+	tests := []struct {
+		name    string
+		c       *Crypto
+		sig     string
+		encrypt string
+		want    error
+	}{
+		{"ok", c, sig, encrypt, nil},
+		{"wrong signature", c, strings.Repeat("0", len(sig)), encrypt, ErrInvalidSignature},
+		{"empty signature", c, "", encrypt, ErrInvalidSignature},
+		{"wrong receive id", other, sig, encrypt, ErrInvalidReceiveID},
+		{"invalid ciphertext", c, c.Signature("1409304348", "nonce", "AAAA"), "AAAA", ErrInvalidCiphertext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.c.VerifyURL(tt.sig, "1409304348", "nonce", tt.encrypt)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("VerifyURL error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && got != "echo" {
+				t.Errorf("VerifyURL = %q, want %q", got, "echo")
+			}
+		})
+	}
+}
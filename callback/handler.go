@@ -0,0 +1,123 @@
+package callback
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// 返回需要被动回复的明文XML（如TextReply的结果），无需回复时返回nil
+type HandlerFunc func(ctx context.Context, m Message) (reply []byte, err error)
+
+// Handler 处理回调请求：GET请求校验URL，POST请求解密后按消息类型或事件分发
+// 企业微信要求5秒内响应，耗时的处理应在HandlerFunc中异步进行
+type Handler struct {
+	crypto *Crypto
+
+	lock     sync.RWMutex
+	messages map[string]HandlerFunc
+	events   map[string]HandlerFunc
+	fallback HandlerFunc
+}
+
+func NewHandler(c *Crypto) *Handler {
+	return &Handler{
+		crypto:   c,
+		messages: map[string]HandlerFunc{},
+		events:   map[string]HandlerFunc{},
+	}
+}
+
+// 处理指定MsgType的消息，如text、image
+func (h *Handler) HandleMessage(msgType string, fn HandlerFunc) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.messages[msgType] = fn
+}
+
+// 处理指定Event的事件，如click、template_card_event
+func (h *Handler) HandleEvent(event string, fn HandlerFunc) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.events[event] = fn
+}
+
+// 处理未注册的消息及事件
+func (h *Handler) HandleDefault(fn HandlerFunc) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.fallback = fn
+}
+
+func (h *Handler) route(m Message) HandlerFunc {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	hd := m.MessageHeader()
+	var fn HandlerFunc
+	if hd.MsgType == "event" {
+		fn = h.events[hd.Event]
+	} else {
+		fn = h.messages[hd.MsgType]
+	}
+	if fn == nil {
+		fn = h.fallback
+	}
+	return fn
+}
+
+// 回调请求体的最大长度
+const maxBodySize = 1 << 20
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	signature, timestamp, nonce := q.Get("msg_signature"), q.Get("timestamp"), q.Get("nonce")
+
+	switch r.Method {
+	case http.MethodGet:
+		echo, err := h.crypto.VerifyURL(signature, timestamp, nonce, q.Get("echostr"))
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		io.WriteString(rw, echo)
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		plain, err := h.crypto.DecryptMessage(signature, timestamp, nonce, body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		m, err := Parse(plain)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fn := h.route(m)
+		if fn == nil {
+			return
+		}
+		reply, err := fn(r.Context(), m)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if reply == nil {
+			return
+		}
+		b, err := h.crypto.EncryptMessage(reply, timestamp, nonce)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("content-type", "application/xml; charset=utf-8")
+		rw.Write(b)
+	default:
+		rw.Header().Set("allow", "GET, POST")
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,189 @@
+package callback
+
+import "encoding/xml"
+
+// 所有消息及事件的公共字段
+type Header struct {
+	// 企业微信CorpID
+	ToUserName string `xml:"ToUserName"`
+	// 成员UserID
+	FromUserName string `xml:"FromUserName"`
+	CreateTime   int64  `xml:"CreateTime"`
+	MsgType      string `xml:"MsgType"`
+	AgentID      int    `xml:"AgentID"`
+	// 仅事件有值
+	Event string `xml:"Event"`
+}
+
+func (h *Header) MessageHeader() *Header {
+	return h
+}
+
+// Parse返回的消息及事件均实现该接口
+type Message interface {
+	MessageHeader() *Header
+}
+
+type TextMessage struct {
+	Header
+	Content string `xml:"Content"`
+	MsgID   string `xml:"MsgId"`
+}
+
+type ImageMessage struct {
+	Header
+	PicURL  string `xml:"PicUrl"`
+	MediaID string `xml:"MediaId"`
+	MsgID   string `xml:"MsgId"`
+}
+
+type VoiceMessage struct {
+	Header
+	MediaID string `xml:"MediaId"`
+	// 语音格式，如amr、speex
+	Format string `xml:"Format"`
+	MsgID  string `xml:"MsgId"`
+}
+
+type VideoMessage struct {
+	Header
+	MediaID      string `xml:"MediaId"`
+	ThumbMediaID string `xml:"ThumbMediaId"`
+	MsgID        string `xml:"MsgId"`
+}
+
+type LocationMessage struct {
+	Header
+	// 纬度
+	LocationX float64 `xml:"Location_X"`
+	// 经度
+	LocationY float64 `xml:"Location_Y"`
+	Scale     int     `xml:"Scale"`
+	Label     string  `xml:"Label"`
+	MsgID     string  `xml:"MsgId"`
+}
+
+type LinkMessage struct {
+	Header
+	Title       string `xml:"Title"`
+	Description string `xml:"Description"`
+	URL         string `xml:"Url"`
+	PicURL      string `xml:"PicUrl"`
+	MsgID       string `xml:"MsgId"`
+}
+
+// subscribe（成员关注）及unsubscribe（成员取消关注）事件
+type SubscribeEvent struct {
+	Header
+}
+
+// 成员进入应用
+type EnterAgentEvent struct {
+	Header
+	EventKey string `xml:"EventKey"`
+}
+
+// 上报地理位置
+type LocationEvent struct {
+	Header
+	Latitude  float64 `xml:"Latitude"`
+	Longitude float64 `xml:"Longitude"`
+	Precision float64 `xml:"Precision"`
+	AppType   string  `xml:"AppType"`
+}
+
+// 点击菜单拉取消息
+type ClickEvent struct {
+	Header
+	EventKey string `xml:"EventKey"`
+}
+
+// 点击菜单跳转链接，EventKey为跳转的URL
+type ViewEvent struct {
+	Header
+	EventKey string `xml:"EventKey"`
+}
+
+type TemplateCardOption struct {
+	QuestionKey string   `xml:"QuestionKey"`
+	OptionIDs   []string `xml:"OptionIds>OptionId"`
+}
+
+// 模板卡片按钮点击事件
+type TemplateCardEvent struct {
+	Header
+	// 按钮的key
+	EventKey string `xml:"EventKey"`
+	TaskID   string `xml:"TaskId"`
+	CardType string `xml:"CardType"`
+	// 用于调用更新模板卡片接口，72小时内有效且只能使用一次
+	ResponseCode  string               `xml:"ResponseCode"`
+	SelectedItems []TemplateCardOption `xml:"SelectedItems>SelectedItem"`
+}
+
+// 任务卡片按钮点击事件
+type TaskCardClickEvent struct {
+	Header
+	EventKey string `xml:"EventKey"`
+	TaskID   string `xml:"TaskId"`
+}
+
+// 未单独定义结构的消息或事件，Raw为完整的明文XML
+type UnknownMessage struct {
+	Header
+	Raw []byte `xml:"-"`
+}
+
+func newMessage(h *Header) Message {
+	if h.MsgType == "event" {
+		switch h.Event {
+		case "subscribe", "unsubscribe":
+			return &SubscribeEvent{}
+		case "enter_agent":
+			return &EnterAgentEvent{}
+		case "LOCATION":
+			return &LocationEvent{}
+		case "click":
+			return &ClickEvent{}
+		case "view":
+			return &ViewEvent{}
+		case "template_card_event":
+			return &TemplateCardEvent{}
+		case "taskcard_click":
+			return &TaskCardClickEvent{}
+		}
+		return nil
+	}
+	switch h.MsgType {
+	case "text":
+		return &TextMessage{}
+	case "image":
+		return &ImageMessage{}
+	case "voice":
+		return &VoiceMessage{}
+	case "video":
+		return &VideoMessage{}
+	case "location":
+		return &LocationMessage{}
+	case "link":
+		return &LinkMessage{}
+	}
+	return nil
+}
+
+// 解析DecryptMessage返回的明文XML，根据MsgType及Event返回对应的结构体指针，如*TextMessage、*ClickEvent
+// 未知类型返回*UnknownMessage
+func Parse(data []byte) (Message, error) {
+	h := &Header{}
+	if err := xml.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	m := newMessage(h)
+	if m == nil {
+		return &UnknownMessage{Header: *h, Raw: data}, nil
+	}
+	if err := xml.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,79 @@
+package callback
+
+import (
+	"encoding/xml"
+	"errors"
+	"time"
+)
+
+// 被动回复消息的公共字段，toUser为成员userid，fromUser为corpid
+type replyHeader struct {
+	XMLName      xml.Name `xml:"xml"`
+	ToUserName   cdata    `xml:"ToUserName"`
+	FromUserName cdata    `xml:"FromUserName"`
+	CreateTime   int64    `xml:"CreateTime"`
+	MsgType      cdata    `xml:"MsgType"`
+}
+
+func newReplyHeader(toUser, fromUser, msgType string) replyHeader {
+	return replyHeader{
+		ToUserName:   cdata{toUser},
+		FromUserName: cdata{fromUser},
+		CreateTime:   time.Now().Unix(),
+		MsgType:      cdata{msgType},
+	}
+}
+
+// 返回的明文需经EncryptMessage加密后响应
+func TextReply(toUser, fromUser, content string) ([]byte, error) {
+	return xml.Marshal(&struct {
+		replyHeader
+		Content cdata `xml:"Content"`
+	}{newReplyHeader(toUser, fromUser, "text"), cdata{content}})
+}
+
+type mediaID struct {
+	MediaID cdata `xml:"MediaId"`
+}
+
+func ImageReply(toUser, fromUser, mediaId string) ([]byte, error) {
+	return xml.Marshal(&struct {
+		replyHeader
+		Image mediaID `xml:"Image"`
+	}{newReplyHeader(toUser, fromUser, "image"), mediaID{cdata{mediaId}}})
+}
+
+type Article struct {
+	Title       string
+	Description string
+	URL         string
+	PicURL      string
+}
+
+type replyArticle struct {
+	Title       cdata `xml:"Title"`
+	Description cdata `xml:"Description"`
+	PicURL      cdata `xml:"PicUrl"`
+	URL         cdata `xml:"Url"`
+}
+
+// 最多8条
+func NewsReply(toUser, fromUser string, articles []Article) ([]byte, error) {
+	if len(articles) == 0 || len(articles) > 8 {
+		return nil, errors.New("callback: news articles count must be between 1 and 8")
+	}
+	items := make([]replyArticle, 0, len(articles))
+	for _, a := range articles {
+		items = append(items, replyArticle{
+			Title:       cdata{a.Title},
+			Description: cdata{a.Description},
+			PicURL:      cdata{a.PicURL},
+			URL:         cdata{a.URL},
+		})
+	}
+	return xml.Marshal(&struct {
+		replyHeader
+		ArticleCount int            `xml:"ArticleCount"`
+		Articles     []replyArticle `xml:"Articles>item"`
+	}{newReplyHeader(toUser, fromUser, "news"), len(items), items})
+}
@@ -0,0 +1,122 @@
+package callback
+
+import (
+	"encoding/xml"
+	"errors"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+// 将模板卡片的按钮替换为不可点击的文案，用于响应TemplateCardEvent
+func UpdateButtonReply(toUser, fromUser, replaceName string) ([]byte, error) {
+	return xml.Marshal(&struct {
+		replyHeader
+		ReplaceName cdata `xml:"Button>ReplaceName"`
+	}{newReplyHeader(toUser, fromUser, "update_button"), cdata{replaceName}})
+}
+
+type xmlCardSource struct {
+	IconURL   cdata `xml:"IconUrl"`
+	Desc      cdata `xml:"Desc"`
+	DescColor int   `xml:"DescColor"`
+}
+
+type xmlCardTitle struct {
+	Title cdata `xml:"Title"`
+	Desc  cdata `xml:"Desc"`
+}
+
+type xmlCardHorizontalContent struct {
+	Type    int   `xml:"Type"`
+	KeyName cdata `xml:"KeyName"`
+	Value   cdata `xml:"Value"`
+	URL     cdata `xml:"Url"`
+	MediaID cdata `xml:"MediaId"`
+	Userid  cdata `xml:"UserId"`
+}
+
+type xmlCardJump struct {
+	Type     int   `xml:"Type"`
+	Title    cdata `xml:"Title"`
+	URL      cdata `xml:"Url"`
+	AppID    cdata `xml:"AppId"`
+	PagePath cdata `xml:"PagePath"`
+}
+
+type xmlCardAction struct {
+	Type     int   `xml:"Type"`
+	URL      cdata `xml:"Url"`
+	AppID    cdata `xml:"AppId"`
+	PagePath cdata `xml:"PagePath"`
+}
+
+type xmlCardButton struct {
+	Text  cdata `xml:"Text"`
+	Style int   `xml:"Style"`
+	Key   cdata `xml:"Key"`
+}
+
+type xmlTemplateCard struct {
+	CardType              cdata                      `xml:"CardType"`
+	Source                *xmlCardSource             `xml:"Source,omitempty"`
+	MainTitle             *xmlCardTitle              `xml:"MainTitle,omitempty"`
+	EmphasisContent       *xmlCardTitle              `xml:"EmphasisContent,omitempty"`
+	SubTitleText          *cdata                     `xml:"SubTitleText,omitempty"`
+	HorizontalContentList []xmlCardHorizontalContent `xml:"HorizontalContentList"`
+	JumpList              []xmlCardJump              `xml:"JumpList"`
+	CardAction            *xmlCardAction             `xml:"CardAction,omitempty"`
+	TaskID                *cdata                     `xml:"TaskId,omitempty"`
+	ButtonList            []xmlCardButton            `xml:"ButtonList"`
+	ReplaceText           *cdata                     `xml:"ReplaceText,omitempty"`
+}
+
+func toXMLCard(c *wecom.TemplateCard, replaceText string) *xmlTemplateCard {
+	x := &xmlTemplateCard{CardType: cdata{string(c.CardType)}}
+	if c.Source != nil {
+		x.Source = &xmlCardSource{cdata{c.Source.IconURL}, cdata{c.Source.Desc}, c.Source.DescColor}
+	}
+	if c.MainTitle != nil {
+		x.MainTitle = &xmlCardTitle{cdata{c.MainTitle.Title}, cdata{c.MainTitle.Desc}}
+	}
+	if c.EmphasisContent != nil {
+		x.EmphasisContent = &xmlCardTitle{cdata{c.EmphasisContent.Title}, cdata{c.EmphasisContent.Desc}}
+	}
+	if c.SubTitleText != "" {
+		x.SubTitleText = &cdata{c.SubTitleText}
+	}
+	for _, h := range c.HorizontalContentList {
+		x.HorizontalContentList = append(x.HorizontalContentList, xmlCardHorizontalContent{
+			h.Type, cdata{h.Keyname}, cdata{h.Value}, cdata{h.URL}, cdata{h.MediaID}, cdata{h.Userid},
+		})
+	}
+	for _, j := range c.JumpList {
+		x.JumpList = append(x.JumpList, xmlCardJump{j.Type, cdata{j.Title}, cdata{j.URL}, cdata{j.Appid}, cdata{j.Pagepath}})
+	}
+	if c.CardAction != nil {
+		a := c.CardAction
+		x.CardAction = &xmlCardAction{a.Type, cdata{a.URL}, cdata{a.Appid}, cdata{a.Pagepath}}
+	}
+	if c.TaskID != "" {
+		x.TaskID = &cdata{c.TaskID}
+	}
+	for _, b := range c.ButtonList {
+		x.ButtonList = append(x.ButtonList, xmlCardButton{cdata{b.Text}, b.Style, cdata{b.Key}})
+	}
+	if replaceText != "" {
+		x.ReplaceText = &cdata{replaceText}
+	}
+	return x
+}
+
+// 用新的模板卡片替换成员点击的卡片，用于响应TemplateCardEvent
+// 支持卡片的来源、标题、关键数据、二级标题、水平内容、跳转列表、整体点击、任务id及按钮，
+// replaceText不为空时替换按钮区域为该文案（仅button_interaction等带按钮的卡片有效）
+func UpdateTemplateCardReply(toUser, fromUser string, card *wecom.TemplateCard, replaceText string) ([]byte, error) {
+	if card == nil {
+		return nil, errors.New("callback: template card is nil")
+	}
+	return xml.Marshal(&struct {
+		replyHeader
+		TemplateCard *xmlTemplateCard `xml:"TemplateCard"`
+	}{newReplyHeader(toUser, fromUser, "update_template_card"), toXMLCard(card, replaceText)})
+}
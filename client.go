@@ -0,0 +1,42 @@
+package wecom
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// Client 包含发送消息相关的方法，New返回的客户端实现了该接口，可用于依赖注入及在测试中替换为mock.Client
+type Client interface {
+	Text(ctx context.Context, t *TextInfo) (*SendResult, error)
+	Markdown(ctx context.Context, m *MarkdownInfo) (*SendResult, error)
+	TextCard(ctx context.Context, t *TextCardInfo) (*SendResult, error)
+	News(ctx context.Context, n *NewsInfo) (*SendResult, error)
+	MPNews(ctx context.Context, n *MPNewsInfo) (*SendResult, error)
+	MiniprogramNotice(ctx context.Context, m *MiniprogramNoticeInfo) (*SendResult, error)
+	Image(ctx context.Context, i *ImageInfo) (*SendResult, error)
+	Voice(ctx context.Context, v *VoiceInfo) (*SendResult, error)
+	Video(ctx context.Context, v *VideoInfo) (*SendResult, error)
+	File(ctx context.Context, f *FileInfo) (*SendResult, error)
+	FileFromPath(ctx context.Context, touser string, agentID int, path string) (*SendResult, error)
+	TemplateCard(ctx context.Context, t *TemplateCardInfo) (*SendResult, error)
+	UpdateTemplateCard(ctx context.Context, u *UpdateTemplateCardInfo) error
+	TaskCard(ctx context.Context, t *TaskCardInfo) (*SendResult, error)
+	UpdateTaskCard(ctx context.Context, agentID int, userids []string, taskID, clickedKey string) error
+	SendRaw(ctx context.Context, msgtype string, body any) (*SendResult, error)
+	SendTemplate(ctx context.Context, name string, data any, r Recipients) (*SendResult, error)
+	BatchText(ctx context.Context, userids []string, t *TextInfo, parallel int) (*BatchResult, error)
+	Recall(ctx context.Context, msgid string) error
+
+	AppChatText(ctx context.Context, chatid, content string, safe bool) error
+	AppChatMarkdown(ctx context.Context, chatid, content string) error
+	AppChatFile(ctx context.Context, chatid string, content []byte, filename string, safe bool) error
+	LinkedCorpSend(ctx context.Context, m *LinkedCorpMessage) (*LinkedCorpResult, error)
+	SchoolSend(ctx context.Context, m *SchoolMessage) (*SchoolResult, error)
+
+	UploadMedia(ctx context.Context, r io.Reader, size int64, filetype Filetype, filename string) (string, error)
+	UploadImage(ctx context.Context, content []byte, filename string) (string, error)
+	Do(ctx context.Context, method, path string, query url.Values, body, out any) error
+}
+
+var _ Client = (*Wecom)(nil)
@@ -0,0 +1,130 @@
+// wecom-push 从命令行发送企业微信应用消息，适用于cron及CI
+//
+//	echo "部署完成" | wecom-push text -to user1
+//	wecom-push markdown -to user1 "**构建失败**"
+//	wecom-push file -to user1 report.pdf
+//
+// corpid、secret及agentid等从环境变量WECOM_CORPID、WECOM_SECRET、WECOM_AGENTID读取，
+// 或通过-config指定YAML配置文件，格式见wecom.Config
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: wecom-push <text|markdown|file|image> [flags] [content|path]
+
+text及markdown未给出content时从标准输入读取`)
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	// 先检查子命令，避免未知命令被报告为缺少接收者或配置
+	switch cmd {
+	case "text", "markdown", "file", "image":
+	default:
+		fmt.Fprintf(os.Stderr, "wecom-push: unknown command %q\n", cmd)
+		usage()
+	}
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	to := fs.String("to", "", "成员userid，多个以|分隔")
+	party := fs.String("party", "", "部门id，多个以|分隔")
+	tag := fs.String("tag", "", "标签id，多个以|分隔")
+	agentID := fs.Int("agentid", 0, "应用AgentID，默认使用配置中的值")
+	configPath := fs.String("config", "", "YAML配置文件路径，默认从环境变量读取")
+	timeout := fs.Duration("timeout", 30*time.Second, "超时时间")
+	fs.Parse(os.Args[2:])
+
+	if err := run(cmd, fs.Args(), *to, *party, *tag, *agentID, *configPath, *timeout); err != nil {
+		fmt.Fprintln(os.Stderr, "wecom-push:", err)
+		os.Exit(1)
+	}
+}
+
+func run(cmd string, args []string, to, party, tag string, agentID int, configPath string, timeout time.Duration) error {
+	if to == "" && party == "" && tag == "" {
+		return errors.New("one of -to, -party or -tag is required")
+	}
+	var (
+		w   wecom.Client
+		err error
+	)
+	if configPath != "" {
+		w, err = wecom.NewFromConfig(configPath)
+	} else {
+		w, err = wecom.NewFromEnv()
+	}
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var r *wecom.SendResult
+	switch cmd {
+	case "text", "markdown":
+		content := strings.Join(args, " ")
+		if content == "" {
+			var b []byte
+			b, err = io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			content = strings.TrimRight(string(b), "\n")
+		}
+		if content == "" {
+			return errors.New("content is empty")
+		}
+		if cmd == "text" {
+			r, err = w.Text(ctx, &wecom.TextInfo{Touser: to, Toparty: party, Totag: tag, AgentID: agentID, Content: content})
+		} else {
+			r, err = w.Markdown(ctx, &wecom.MarkdownInfo{Touser: to, Toparty: party, Totag: tag, AgentID: agentID, Content: content})
+		}
+	case "file", "image":
+		if len(args) != 1 {
+			return errors.New(cmd + " requires exactly one path")
+		}
+		var content []byte
+		content, err = os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		filetype := wecom.FILE
+		if cmd == "image" {
+			filetype = wecom.IMAGE
+		}
+		r, err = w.File(ctx, &wecom.FileInfo{
+			Touser:   to,
+			Toparty:  party,
+			Totag:    tag,
+			AgentID:  agentID,
+			Content:  content,
+			Filetype: filetype,
+			Filename: filepath.Base(args[0]),
+		})
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+	if err != nil {
+		return err
+	}
+	if r.HasInvalid() {
+		fmt.Fprintf(os.Stderr, "wecom-push: invalid recipients: user=%q party=%q tag=%q\n", r.InvalidUser, r.InvalidParty, r.InvalidTag)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package wecom
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NewFromConfig读取的配置文件格式
+type Config struct {
+	Corpid  string `yaml:"corpid"`
+	Secret  string `yaml:"secret"`
+	AgentID int    `yaml:"agentid"`
+	// 如http://127.0.0.1:8080
+	Proxy string `yaml:"proxy"`
+	// 如10s
+	Timeout time.Duration `yaml:"timeout"`
+	BaseURL string        `yaml:"base_url"`
+}
+
+func (c *Config) new(opts []Option) (*Wecom, error) {
+	if c.Corpid == "" || c.Secret == "" {
+		return nil, errors.New("wecom: corpid and secret are required")
+	}
+	var o []Option
+	if c.AgentID != 0 {
+		o = append(o, WithAgentID(c.AgentID))
+	}
+	if c.Proxy != "" {
+		o = append(o, WithProxy(c.Proxy))
+	}
+	if c.Timeout > 0 {
+		o = append(o, WithTimeout(c.Timeout))
+	}
+	if c.BaseURL != "" {
+		o = append(o, WithBaseURL(c.BaseURL))
+	}
+	// opts在配置之后应用，可覆盖配置中的值
+	return New(c.Corpid, c.Secret, append(o, opts...)...), nil
+}
+
+// 从环境变量WECOM_CORPID、WECOM_SECRET、WECOM_AGENTID、WECOM_PROXY、WECOM_TIMEOUT及WECOM_BASE_URL创建客户端
+func NewFromEnv(opts ...Option) (*Wecom, error) {
+	c := &Config{
+		Corpid:  os.Getenv("WECOM_CORPID"),
+		Secret:  os.Getenv("WECOM_SECRET"),
+		Proxy:   os.Getenv("WECOM_PROXY"),
+		BaseURL: os.Getenv("WECOM_BASE_URL"),
+	}
+	if v := os.Getenv("WECOM_AGENTID"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("wecom: invalid WECOM_AGENTID: %w", err)
+		}
+		c.AgentID = id
+	}
+	if v := os.Getenv("WECOM_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("wecom: invalid WECOM_TIMEOUT: %w", err)
+		}
+		c.Timeout = d
+	}
+	return c.new(opts)
+}
+
+// 从YAML配置文件创建客户端，JSON是YAML的子集，同样支持
+func NewFromConfig(path string, opts ...Option) (*Wecom, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("wecom: parse %s: %w", path, err)
+	}
+	return c.new(opts)
+}
@@ -0,0 +1,21 @@
+// Package contacts 封装通讯录管理接口，复用wecom客户端的access_token
+package contacts
+
+import (
+	"context"
+	"net/url"
+)
+
+// Doer 由wecom.New返回的客户端实现
+type Doer interface {
+	Do(ctx context.Context, method, path string, query url.Values, body, out any) error
+}
+
+type Client struct {
+	d Doer
+}
+
+// 通讯录接口需要应用具有相应的通讯录权限
+func New(d Doer) *Client {
+	return &Client{d: d}
+}
@@ -0,0 +1,44 @@
+package contacts
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type Department struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	NameEn   string `json:"name_en"`
+	ParentID int    `json:"parentid"`
+	Order    int    `json:"order"`
+	// 部门负责人的userid列表
+	DepartmentLeader []string `json:"department_leader"`
+}
+
+// 获取部门列表，id为0时获取全量组织架构，否则获取该部门及其下的子部门
+func (c *Client) ListDepartments(ctx context.Context, id int) ([]Department, error) {
+	var q url.Values
+	if id != 0 {
+		q = url.Values{"id": {strconv.Itoa(id)}}
+	}
+	r := struct {
+		Department []Department `json:"department"`
+	}{}
+	if err := c.d.Do(ctx, http.MethodGet, "department/list", q, nil, &r); err != nil {
+		return nil, err
+	}
+	return r.Department, nil
+}
+
+// 获取单个部门详情，部门不存在时返回*wecom.Error
+func (c *Client) GetDepartment(ctx context.Context, id int) (*Department, error) {
+	r := struct {
+		Department *Department `json:"department"`
+	}{}
+	if err := c.d.Do(ctx, http.MethodGet, "department/get", url.Values{"id": {strconv.Itoa(id)}}, nil, &r); err != nil {
+		return nil, err
+	}
+	return r.Department, nil
+}
@@ -0,0 +1,59 @@
+package contacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 通过手机号获取userid
+func (c *Client) UserIDByMobile(ctx context.Context, mobile string) (string, error) {
+	return c.userid(ctx, "user/getuserid", map[string]any{"mobile": mobile})
+}
+
+// 通过邮箱获取userid，同时匹配企业邮箱与个人邮箱
+func (c *Client) UserIDByEmail(ctx context.Context, email string) (string, error) {
+	return c.userid(ctx, "user/get_userid_by_email", map[string]any{"email": email})
+}
+
+func (c *Client) userid(ctx context.Context, path string, d map[string]any) (string, error) {
+	r := struct {
+		Userid string `json:"userid"`
+	}{}
+	if err := c.d.Do(ctx, http.MethodPost, path, nil, d, &r); err != nil {
+		return "", err
+	}
+	return r.Userid, nil
+}
+
+// 批量将手机号或邮箱（含@）解析为userid，返回成功解析的部分
+// 有解析失败的条目时同时返回错误，错误中包含每个失败条目的原因
+func (c *Client) BatchResolve(ctx context.Context, keys []string) (map[string]string, error) {
+	m := make(map[string]string, len(keys))
+	var errs []error
+	for _, k := range keys {
+		if _, ok := m[k]; ok {
+			continue
+		}
+		var (
+			id  string
+			err error
+		)
+		if strings.Contains(k, "@") {
+			id, err = c.UserIDByEmail(ctx, k)
+		} else {
+			id, err = c.UserIDByMobile(ctx, k)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return m, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("resolve %s: %w", k, err))
+			continue
+		}
+		m[k] = id
+	}
+	return m, errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+package contacts
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type Tag struct {
+	TagID   int    `json:"tagid"`
+	TagName string `json:"tagname"`
+}
+
+// 创建标签，tagID为0时由系统自动生成，返回标签id
+func (c *Client) CreateTag(ctx context.Context, name string, tagID int) (int, error) {
+	d := map[string]any{"tagname": name}
+	if tagID != 0 {
+		d["tagid"] = tagID
+	}
+	r := struct {
+		TagID int `json:"tagid"`
+	}{}
+	if err := c.d.Do(ctx, http.MethodPost, "tag/create", nil, d, &r); err != nil {
+		return 0, err
+	}
+	return r.TagID, nil
+}
+
+// 增删标签成员时部分userid或部门id无效的情况
+type TagUsersResult struct {
+	// 非法的成员帐号列表，以“|”分隔
+	InvalidList  string `json:"invalidlist"`
+	InvalidParty []int  `json:"invalidparty"`
+}
+
+// 增加标签成员，userlist与partylist不能同时为空，单次均不超过1000个
+func (c *Client) AddTagUsers(ctx context.Context, tagID int, userlist []string, partylist []int) (*TagUsersResult, error) {
+	return c.tagUsers(ctx, "tag/addtagusers", tagID, userlist, partylist)
+}
+
+// 删除标签成员，userlist与partylist不能同时为空，单次均不超过1000个
+func (c *Client) DeleteTagUsers(ctx context.Context, tagID int, userlist []string, partylist []int) (*TagUsersResult, error) {
+	return c.tagUsers(ctx, "tag/deltagusers", tagID, userlist, partylist)
+}
+
+func (c *Client) tagUsers(ctx context.Context, path string, tagID int, userlist []string, partylist []int) (*TagUsersResult, error) {
+	r := &TagUsersResult{}
+	err := c.d.Do(ctx, http.MethodPost, path, nil, map[string]any{
+		"tagid":     tagID,
+		"userlist":  userlist,
+		"partylist": partylist,
+	}, r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// 获取标签列表
+func (c *Client) ListTags(ctx context.Context) ([]Tag, error) {
+	r := struct {
+		Taglist []Tag `json:"taglist"`
+	}{}
+	if err := c.d.Do(ctx, http.MethodGet, "tag/list", nil, nil, &r); err != nil {
+		return nil, err
+	}
+	return r.Taglist, nil
+}
+
+type TagUsers struct {
+	TagName  string `json:"tagname"`
+	Userlist []struct {
+		Userid string `json:"userid"`
+		Name   string `json:"name"`
+	} `json:"userlist"`
+	Partylist []int `json:"partylist"`
+}
+
+// 获取标签成员
+func (c *Client) GetTagUsers(ctx context.Context, tagID int) (*TagUsers, error) {
+	r := &TagUsers{}
+	if err := c.d.Do(ctx, http.MethodGet, "tag/get", url.Values{"tagid": {strconv.Itoa(tagID)}}, nil, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
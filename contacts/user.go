@@ -0,0 +1,108 @@
+package contacts
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type ExtAttr struct {
+	Type int    `json:"type"`
+	Name string `json:"name"`
+	Text *struct {
+		Value string `json:"value"`
+	} `json:"text,omitempty"`
+	Web *struct {
+		URL   string `json:"url"`
+		Title string `json:"title"`
+	} `json:"web,omitempty"`
+}
+
+type User struct {
+	Userid string `json:"userid"`
+	Name   string `json:"name,omitempty"`
+	Alias  string `json:"alias,omitempty"`
+	Mobile string `json:"mobile,omitempty"`
+	// 所属部门id列表
+	Department []int `json:"department,omitempty"`
+	// 在所属部门内的排序值，与Department一一对应
+	Order []int `json:"order,omitempty"`
+	// 在所属部门内是否为部门负责人，与Department一一对应，1为是
+	IsLeaderInDept []int  `json:"is_leader_in_dept,omitempty"`
+	Position       string `json:"position,omitempty"`
+	// 1男性，2女性
+	Gender         string `json:"gender,omitempty"`
+	Email          string `json:"email,omitempty"`
+	BizMail        string `json:"biz_mail,omitempty"`
+	Telephone      string `json:"telephone,omitempty"`
+	MainDepartment int    `json:"main_department,omitempty"`
+	// 1已激活，2已禁用，4未激活，5退出企业
+	Status     int    `json:"status,omitempty"`
+	Avatar     string `json:"avatar,omitempty"`
+	OpenUserid string `json:"open_userid,omitempty"`
+	Extattr    *struct {
+		Attrs []ExtAttr `json:"attrs"`
+	} `json:"extattr,omitempty"`
+}
+
+// 读取成员
+func (c *Client) GetUser(ctx context.Context, userid string) (*User, error) {
+	u := &User{}
+	if err := c.d.Do(ctx, http.MethodGet, "user/get", url.Values{"userid": {userid}}, nil, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// 创建成员，Userid、Name、Department必填，Mobile与Email不能同时为空
+func (c *Client) CreateUser(ctx context.Context, u *User) error {
+	return c.d.Do(ctx, http.MethodPost, "user/create", nil, u, nil)
+}
+
+// 更新成员，仅更新非零值字段
+func (c *Client) UpdateUser(ctx context.Context, u *User) error {
+	return c.d.Do(ctx, http.MethodPost, "user/update", nil, u, nil)
+}
+
+// 删除成员
+func (c *Client) DeleteUser(ctx context.Context, userid string) error {
+	return c.d.Do(ctx, http.MethodGet, "user/delete", url.Values{"userid": {userid}}, nil, nil)
+}
+
+type SimpleUser struct {
+	Userid     string `json:"userid"`
+	Name       string `json:"name"`
+	Department []int  `json:"department"`
+	OpenUserid string `json:"open_userid"`
+}
+
+// 获取部门成员的userid与姓名，fetchChild为true时递归获取子部门成员
+func (c *Client) SimpleListUsers(ctx context.Context, departmentID int, fetchChild bool) ([]SimpleUser, error) {
+	r := struct {
+		Userlist []SimpleUser `json:"userlist"`
+	}{}
+	if err := c.d.Do(ctx, http.MethodGet, "user/simplelist", departmentQuery(departmentID, fetchChild), nil, &r); err != nil {
+		return nil, err
+	}
+	return r.Userlist, nil
+}
+
+// 获取部门成员详情，fetchChild为true时递归获取子部门成员
+func (c *Client) ListUsers(ctx context.Context, departmentID int, fetchChild bool) ([]User, error) {
+	r := struct {
+		Userlist []User `json:"userlist"`
+	}{}
+	if err := c.d.Do(ctx, http.MethodGet, "user/list", departmentQuery(departmentID, fetchChild), nil, &r); err != nil {
+		return nil, err
+	}
+	return r.Userlist, nil
+}
+
+func departmentQuery(departmentID int, fetchChild bool) url.Values {
+	q := url.Values{"department_id": {strconv.Itoa(departmentID)}}
+	if fetchChild {
+		q.Set("fetch_child", "1")
+	}
+	return q
+}
@@ -0,0 +1,80 @@
+package wecom
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 一个应用secret及其对应的access_token
+type credential struct {
+	secret      string
+	lock        sync.RWMutex
+	accessToken string
+	expiresAt   time.Time
+	// 最近一次确认失效的access_token
+	invalid string
+}
+
+func (c *credential) set(token string, ttl time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.accessToken = token
+	c.expiresAt = time.Now().Add(ttl)
+}
+
+func (c *credential) get() (string, time.Time) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.accessToken, c.expiresAt
+}
+
+// 返回access_token及其是否未过期
+func (c *credential) valid() (string, bool) {
+	token, expiresAt := c.get()
+	return token, token != "" && time.Now().Before(expiresAt)
+}
+
+// 仅当当前access_token仍为token时清除，避免清除其他调用者刚获取的access_token
+func (c *credential) invalidate(token string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.accessToken == token {
+		c.accessToken = ""
+	}
+	c.invalid = token
+}
+
+func (c *credential) isInvalid(token string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.invalid == token
+}
+
+// 注册同一企业下另一个应用的secret，向该应用发送消息时使用其自身的access_token
+// 可多次调用注册多个应用，未注册的agentID使用New传入的corpsecret
+func WithAgent(agentID int, secret string) Option {
+	return func(w *Wecom) {
+		if w.agents == nil {
+			w.agents = map[int]*credential{}
+		}
+		w.agents[agentID] = &credential{secret: secret}
+	}
+}
+
+type agentKey struct{}
+
+// 使ctx中的调用使用agentID对应应用的access_token，用于撤回消息、上传素材等请求中不含agentid的接口
+// 发送应用消息时根据消息的AgentID自动选择，无需调用
+func WithAgentContext(ctx context.Context, agentID int) context.Context {
+	return context.WithValue(ctx, agentKey{}, agentID)
+}
+
+func (w *Wecom) credentialFor(ctx context.Context) *credential {
+	if agentID, ok := ctx.Value(agentKey{}).(int); ok {
+		if c, ok := w.agents[agentID]; ok {
+			return c
+		}
+	}
+	return w.credential
+}
@@ -0,0 +1,44 @@
+package wecom
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// 演练模式下上传素材返回的media_id
+const dryRunMediaID = "dry-run-media-id"
+
+// 演练模式：所有接口调用（应用消息、群机器人消息、素材上传、卡片更新、撤回等）均不调用企业微信接口，只输出请求的接口及内容
+// 输出到WithLogger设置的Logger，未设置时输出到标准库log；不获取access_token，接口返回errcode为0的空响应，上传素材返回固定的media_id
+func WithDryRun() Option {
+	return func(w *Wecom) {
+		w.dryRun = true
+	}
+}
+
+func (w *Wecom) logDryRun(format string, v ...any) {
+	var l Logger = w.logger
+	if _, ok := l.(nopLogger); ok {
+		l = log.Default()
+	}
+	l.Printf("wecom: dry run: "+format, v...)
+}
+
+// 代替实际请求，JSON请求体原样输出，文件等其他请求体只输出大小
+func (w *Wecom) dryRunRequest(r *http.Request) (http.Header, []byte, error) {
+	switch {
+	case r.Body == nil:
+		w.logDryRun("%s %s", r.Method, endpoint(r))
+	case strings.HasPrefix(r.Header.Get("content-type"), "application/json"):
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		w.logDryRun("%s %s %s", r.Method, endpoint(r), b)
+	default:
+		w.logDryRun("%s %s size=%d", r.Method, endpoint(r), r.ContentLength)
+	}
+	return http.Header{"Content-Type": {"application/json"}}, []byte(`{"errcode":0,"errmsg":"ok"}`), nil
+}
@@ -0,0 +1,231 @@
+package wecom
+
+// 全局错误码说明，见https://developer.work.weixin.qq.com/document/path/90313
+type ErrcodeInfo struct {
+	Zh string
+	En string
+}
+
+var errcodes = map[int]ErrcodeInfo{
+	-1:      {"系统繁忙", "system busy"},
+	0:       {"请求成功", "ok"},
+	40001:   {"不合法的secret参数", "invalid secret"},
+	40003:   {"无效的UserID", "invalid userid"},
+	40004:   {"不合法的媒体文件类型", "invalid media type"},
+	40005:   {"不合法的type参数", "invalid type"},
+	40006:   {"不合法的文件大小", "invalid file size"},
+	40007:   {"不合法的media_id参数", "invalid media_id"},
+	40008:   {"不合法的msgtype参数", "invalid msgtype"},
+	40009:   {"上传图片大小不是有效值", "invalid image size"},
+	40011:   {"上传视频大小不是有效值", "invalid video size"},
+	40013:   {"不合法的CorpID", "invalid corpid"},
+	40014:   {"不合法的access_token", "invalid access_token"},
+	40016:   {"不合法的按钮个数", "invalid button count"},
+	40017:   {"不合法的按钮类型", "invalid button type"},
+	40018:   {"不合法的按钮名字长度", "invalid button name length"},
+	40019:   {"不合法的按钮KEY长度", "invalid button key length"},
+	40020:   {"不合法的按钮URL长度", "invalid button url length"},
+	40022:   {"不合法的子菜单级数", "invalid sub menu level"},
+	40023:   {"不合法的子菜单按钮个数", "invalid sub button count"},
+	40024:   {"不合法的子菜单按钮类型", "invalid sub button type"},
+	40025:   {"不合法的子菜单按钮名字长度", "invalid sub button name length"},
+	40026:   {"不合法的子菜单按钮KEY长度", "invalid sub button key length"},
+	40027:   {"不合法的子菜单按钮URL长度", "invalid sub button url length"},
+	40029:   {"不合法的oauth_code", "invalid oauth code"},
+	40031:   {"不合法的UserID列表", "invalid userid list"},
+	40032:   {"不合法的UserID列表长度", "invalid userid list length"},
+	40033:   {"不合法的请求字符", "invalid character in request"},
+	40035:   {"不合法的参数", "invalid parameter"},
+	40036:   {"不合法的template_id长度", "invalid template_id length"},
+	40037:   {"无效的template_id", "invalid template_id"},
+	40039:   {"不合法的url长度", "invalid url length"},
+	40050:   {"chatid不存在", "chatid not found"},
+	40054:   {"不合法的子菜单url域名", "invalid sub menu url domain"},
+	40055:   {"不合法的菜单url域名", "invalid menu url domain"},
+	40056:   {"不合法的agentid", "invalid agentid"},
+	40057:   {"不合法的callbackurl或者callbackurl验证失败", "invalid callback url"},
+	40058:   {"不合法的参数", "invalid parameter"},
+	40059:   {"不合法的上报地理位置标志位", "invalid report location flag"},
+	40063:   {"参数为空", "empty parameter"},
+	40066:   {"不合法的部门列表", "invalid department list"},
+	40068:   {"不合法的标签ID", "invalid tagid"},
+	40070:   {"指定的标签范围结点全部无效", "all specified tag nodes invalid"},
+	40071:   {"不合法的标签名字", "invalid tag name"},
+	40072:   {"不合法的名字", "invalid name"},
+	40074:   {"news消息不支持保密消息类型", "news message does not support safe"},
+	40078:   {"不合法的状态", "invalid state"},
+	40079:   {"不合法的企业全局参数", "invalid corp parameter"},
+	40080:   {"不合法的suitesecret", "invalid suite secret"},
+	40082:   {"不合法的suitetoken", "invalid suite token"},
+	40083:   {"不合法的suiteid", "invalid suite id"},
+	40084:   {"不合法的永久授权码", "invalid permanent code"},
+	40085:   {"不合法的suiteticket", "invalid suite ticket"},
+	40086:   {"不合法的第三方应用appid", "invalid third party app id"},
+	40088:   {"jobid不存在", "jobid not found"},
+	40089:   {"批量任务的结果已清理", "batch job result cleared"},
+	40091:   {"secret不合法", "invalid secret"},
+	40092:   {"导入文件存在不合法的内容", "invalid content in import file"},
+	40093:   {"jsapi签名错误", "invalid jsapi signature"},
+	40094:   {"不合法的URL", "invalid url"},
+	40096:   {"不合法的外部联系人userid", "invalid external userid"},
+	40097:   {"该成员尚未离职", "user has not resigned"},
+	40098:   {"成员尚未实名认证", "user not verified"},
+	40099:   {"外部联系人的数量已达上限", "external contact count exceeds limit"},
+	40100:   {"此用户的外部联系人已经在转移流程中", "external contacts already in transfer"},
+	40123:   {"上传临时图片素材，图片格式非法", "invalid image format"},
+	40125:   {"无效的openuserid参数", "invalid open_userid"},
+	40126:   {"企业标签个数达到上限", "corp tag count exceeds limit"},
+	40127:   {"不支持的uri schema", "unsupported uri schema"},
+	40128:   {"客户转接过于频繁", "customer transfer too frequent"},
+	40129:   {"当前客户正在转接中", "customer is being transferred"},
+	40130:   {"原跟进人与接手人一样", "handover user is the same as takeover user"},
+	41001:   {"缺少access_token参数", "access_token missing"},
+	41002:   {"缺少corpid参数", "corpid missing"},
+	41004:   {"缺少secret参数", "secret missing"},
+	41006:   {"缺少media_id参数", "media_id missing"},
+	41008:   {"缺少auth code参数", "auth code missing"},
+	41009:   {"缺少userid参数", "userid missing"},
+	41010:   {"缺少url参数", "url missing"},
+	41011:   {"缺少agentid参数", "agentid missing"},
+	41016:   {"缺少title参数", "title missing"},
+	41017:   {"缺少tagid参数", "tagid missing"},
+	41019:   {"缺少department参数", "department missing"},
+	41021:   {"缺少suite_id参数", "suite_id missing"},
+	41022:   {"缺少suite_access_token参数", "suite_access_token missing"},
+	41023:   {"缺少pre_auth_code参数", "pre_auth_code missing"},
+	41024:   {"缺少suite_secret参数", "suite_secret missing"},
+	41025:   {"缺少permanent_code参数", "permanent_code missing"},
+	41033:   {"缺少description参数", "description missing"},
+	41034:   {"缺少login_ticket参数", "login_ticket missing"},
+	41035:   {"缺少跳转链接参数", "redirect link missing"},
+	42001:   {"access_token已过期", "access_token expired"},
+	42007:   {"pre_auth_code已过期", "pre_auth_code expired"},
+	42009:   {"suite_access_token已过期", "suite_access_token expired"},
+	42012:   {"jsapi_ticket不可用，一般是没有正确调用接口来创建jsapi_ticket", "jsapi_ticket unavailable"},
+	42013:   {"小程序未登陆或登录态已经过期", "mini program session expired"},
+	42014:   {"任务卡片消息的task_id不合法", "invalid taskcard task_id"},
+	42015:   {"更新的消息的应用与发送消息的应用不匹配", "updating app does not match sending app"},
+	42016:   {"更新的task_id不存在", "task_id not found"},
+	42017:   {"按钮key值不存在", "button key not found"},
+	42018:   {"按钮key值不合法", "invalid button key"},
+	42019:   {"缺少按钮key值不合法", "button key missing"},
+	42020:   {"缺少按钮名称", "button name missing"},
+	42022:   {"code已经被使用过", "code already used"},
+	43004:   {"指定的userid未绑定微信或未关注微工作台", "user not bound to wechat or not following"},
+	43009:   {"企业未验证主体", "corp not verified"},
+	44001:   {"多媒体文件为空", "empty media file"},
+	44004:   {"文本消息content参数为空", "empty text content"},
+	44005:   {"空数组", "empty array"},
+	45001:   {"多媒体文件大小超过限制", "media file size exceeds limit"},
+	45002:   {"消息内容大小超过限制", "message content size exceeds limit"},
+	45004:   {"应用description参数长度不符合系统限制", "invalid app description length"},
+	45007:   {"语音播放时间超过限制", "voice duration exceeds limit"},
+	45008:   {"图文消息的文章数量不符合系统限制", "invalid news article count"},
+	45009:   {"接口调用超过限制", "api freq out of limit"},
+	45022:   {"应用name参数长度不符合系统限制", "invalid app name length"},
+	45024:   {"帐号数量超过上限", "account count exceeds limit"},
+	45026:   {"触发删除用户数的保护", "user deletion protection triggered"},
+	45032:   {"图文消息author参数长度超过限制", "news author too long"},
+	45033:   {"接口并发调用超过限制", "api concurrency out of limit"},
+	46003:   {"菜单未设置", "menu not set"},
+	46004:   {"指定的用户不存在", "user not found"},
+	48002:   {"API接口无权限调用", "api unauthorized"},
+	48003:   {"不合法的suite_id", "invalid suite_id"},
+	48004:   {"授权关系无效", "invalid authorization"},
+	48005:   {"API接口已废弃", "api deprecated"},
+	48006:   {"接口权限被收回", "api permission revoked"},
+	49004:   {"签名不匹配", "signature mismatch"},
+	50001:   {"redirect_url未登记可信域名", "redirect_url domain not trusted"},
+	50002:   {"成员不在权限范围", "user out of permission scope"},
+	50003:   {"应用已禁用", "app disabled"},
+	60001:   {"部门长度不符合限制", "invalid department name length"},
+	60003:   {"部门ID不存在", "department not found"},
+	60004:   {"父部门不存在", "parent department not found"},
+	60005:   {"部门下存在成员", "department has users"},
+	60006:   {"部门下存在子部门", "department has sub departments"},
+	60007:   {"不允许删除根部门", "root department cannot be deleted"},
+	60008:   {"部门已存在", "department already exists"},
+	60009:   {"部门名称含有非法字符", "invalid character in department name"},
+	60010:   {"部门存在循环关系", "department cycle detected"},
+	60011:   {"指定的成员/部门/标签参数无权限", "no permission for user/department/tag"},
+	60012:   {"不允许删除默认应用", "default app cannot be deleted"},
+	60020:   {"访问ip不在白名单之内", "not allow to access from your ip"},
+	60021:   {"userid不在应用可见范围内", "userid not in app visible scope"},
+	60028:   {"不允许修改第三方应用的主页URL", "third party app home url cannot be modified"},
+	60102:   {"UserID已存在", "userid already exists"},
+	60103:   {"手机号码不合法", "invalid mobile"},
+	60104:   {"手机号码已存在", "mobile already exists"},
+	60105:   {"邮箱不合法", "invalid email"},
+	60106:   {"邮箱已存在", "email already exists"},
+	60107:   {"微信号不合法", "invalid wechat id"},
+	60110:   {"用户所属部门数量超过限制", "user department count exceeds limit"},
+	60111:   {"UserID不存在", "userid not found"},
+	60112:   {"成员name参数不合法", "invalid user name"},
+	60123:   {"无效的部门id", "invalid department id"},
+	60124:   {"无效的父部门id", "invalid parent department id"},
+	60125:   {"非法部门名字", "invalid department name"},
+	60127:   {"缺少department参数", "department missing"},
+	60129:   {"成员手机和邮箱都为空", "user mobile and email both empty"},
+	60132:   {"is_leader_in_dept和department的元素个数不一致", "is_leader_in_dept and department length mismatch"},
+	60136:   {"记录不存在", "record not found"},
+	60203:   {"不合法的模版ID", "invalid template id"},
+	60204:   {"模版状态不可用", "template unavailable"},
+	60205:   {"模版关键词不匹配", "template keywords mismatch"},
+	60206:   {"该种类型的消息只支持第三方独立应用使用", "message type only supported by third party apps"},
+	60207:   {"第三方独立应用只允许发送模板消息", "third party app can only send template messages"},
+	60208:   {"第三方独立应用不支持指定@all，不支持参数toparty和totag", "third party app does not support @all, toparty or totag"},
+	81001:   {"部门下的结点数超过限制", "department node count exceeds limit"},
+	81002:   {"部门最多15层", "department depth exceeds 15"},
+	81011:   {"无权限操作标签", "no permission for tag"},
+	81012:   {"缺失可见范围", "visible scope missing"},
+	81013:   {"UserID、部门ID、标签ID全部非法或无权限", "user & party & tag all invalid"},
+	81014:   {"标签添加成员，单次添加user或party过多", "too many users or parties added to tag"},
+	82001:   {"指定的成员/部门/标签全部为空", "user/party/tag all empty"},
+	82002:   {"不合法的PartyID列表长度", "invalid party list length"},
+	82003:   {"不合法的TagID列表长度", "invalid tag list length"},
+	84014:   {"成员票据过期", "user ticket expired"},
+	84015:   {"成员票据无效", "invalid user ticket"},
+	84019:   {"缺少templateid参数", "templateid missing"},
+	84020:   {"templateid不存在", "templateid not found"},
+	84021:   {"缺少register_code参数", "register_code missing"},
+	84022:   {"无效的register_code参数", "invalid register_code"},
+	84061:   {"不存在外部联系人的关系", "external contact relation not found"},
+	85002:   {"包含不合法的词语", "contains illegal words"},
+	86001:   {"参数chatid不合法", "invalid chatid"},
+	86003:   {"参数chatid不存在", "chatid not found"},
+	86004:   {"参数群名不合法", "invalid chat name"},
+	86005:   {"参数群主不合法", "invalid chat owner"},
+	86006:   {"群成员数过多或过少", "invalid chat member count"},
+	86007:   {"不合法的群成员", "invalid chat member"},
+	86008:   {"非企业内部群", "not a corp internal chat"},
+	86216:   {"存在非法会话成员ID", "invalid chat member id"},
+	86217:   {"会话发送者不在会话成员列表中", "sender not in chat members"},
+	86220:   {"指定的会话参数不合法", "invalid chat parameter"},
+	90001:   {"未认证摇一摇周边", "shake around not verified"},
+	91040:   {"获取ticket的类型无效", "invalid ticket type"},
+	301002:  {"无权限操作指定的应用", "no permission for app"},
+	301005:  {"不允许删除创建者", "creator cannot be deleted"},
+	301012:  {"参数position不合法", "invalid position"},
+	301013:  {"参数telephone不合法", "invalid telephone"},
+	301014:  {"参数english_name不合法", "invalid english_name"},
+	301015:  {"参数mediaid不合法", "invalid mediaid"},
+	301016:  {"上传语音文件不符合系统要求", "voice file does not meet requirements"},
+	301017:  {"上传语音文件仅支持AMR格式", "voice file must be amr"},
+	301021:  {"参数userid无效", "invalid userid"},
+	301023:  {"useridlist非法或超过限额", "invalid or too long useridlist"},
+	301036:  {"不允许更新该用户的userid", "userid cannot be updated"},
+	301039:  {"请求参数错误，请检查输入参数", "invalid request parameters"},
+	301042:  {"ip白名单限制，请求ip不在设置白名单范围", "ip not in whitelist"},
+	302003:  {"批量导入任务的文件中userid有重复", "duplicate userid in import file"},
+	302004:  {"组织架构不合法", "invalid organization structure"},
+	302005:  {"批量导入系统失败，请重新尝试导入", "batch import failed"},
+	302006:  {"批量导入任务的文件中partyid有重复", "duplicate partyid in import file"},
+	302007:  {"批量导入任务的文件中，同一个部门下有重名子部门", "duplicate sub department name in import file"},
+	2000002: {"CorpId参数无效", "invalid corpid"},
+}
+
+// 查询错误码的中英文说明
+func LookupErrcode(errcode int) (ErrcodeInfo, bool) {
+	i, ok := errcodes[errcode]
+	return i, ok
+}
@@ -0,0 +1,33 @@
+package wecom
+
+import "fmt"
+
+// 企业微信接口返回的错误，可通过errors.As获取错误码，或通过errors.Is与下列错误比较
+type Error struct {
+	Errcode int
+	Errmsg  string
+}
+
+func (e *Error) Error() string {
+	if i, ok := LookupErrcode(e.Errcode); ok {
+		return fmt.Sprintf("wecom: errcode %d: %s (%s)", e.Errcode, e.Errmsg, i.Zh)
+	}
+	return fmt.Sprintf("wecom: errcode %d: %s", e.Errcode, e.Errmsg)
+}
+
+// 错误码相同即视为同一错误，忽略Errmsg
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Errcode == e.Errcode
+}
+
+var (
+	ErrSystemBusy         = &Error{Errcode: -1, Errmsg: "system busy"}
+	ErrInvalidCredential  = &Error{Errcode: 40001, Errmsg: "invalid credential"}
+	ErrInvalidUserID      = &Error{Errcode: 40003, Errmsg: "invalid userid"}
+	ErrInvalidAccessToken = &Error{Errcode: 40014, Errmsg: "invalid access_token"}
+	ErrAccessTokenExpired = &Error{Errcode: 42001, Errmsg: "access_token expired"}
+	ErrAPIFreqOutOfLimit  = &Error{Errcode: 45009, Errmsg: "api freq out of limit"}
+	ErrIPNotAllowed       = &Error{Errcode: 60020, Errmsg: "not allow to access from your ip"}
+	ErrInvalidRecipients  = &Error{Errcode: 81013, Errmsg: "user & party & tag all invalid"}
+)
@@ -0,0 +1,184 @@
+package gitnotify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+type GitHubHandler struct {
+	s      Sender
+	router Router
+	secret string
+}
+
+// secret为webhook的Secret，为空时不校验签名
+// 支持push、pull_request及workflow_run事件，其余事件直接返回200
+func NewGitHubHandler(s Sender, router Router, secret string) *GitHubHandler {
+	return &GitHubHandler{s: s, router: router, secret: secret}
+}
+
+type githubRepo struct {
+	FullName string `json:"full_name"`
+	HTMLURL  string `json:"html_url"`
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+func (h *GitHubHandler) verify(r *http.Request, body []byte) bool {
+	if h.secret == "" {
+		return true
+	}
+	sig, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(h.secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func (h *GitHubHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(rw)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, 5<<20))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.verify(r, body) {
+		http.Error(rw, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	n, err := githubNotification(r.Header.Get("X-GitHub-Event"), body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if n != nil {
+		if err := send(r.Context(), h.s, h.router, n); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// 不需要推送的事件返回nil
+func githubNotification(event string, body []byte) (*notification, error) {
+	switch event {
+	case "push":
+		p := struct {
+			Ref     string `json:"ref"`
+			Deleted bool   `json:"deleted"`
+			Pusher  struct {
+				Name string `json:"name"`
+			} `json:"pusher"`
+			Repository githubRepo `json:"repository"`
+			Commits    []struct {
+				ID      string `json:"id"`
+				Message string `json:"message"`
+				URL     string `json:"url"`
+				Author  struct {
+					Name string `json:"name"`
+				} `json:"author"`
+			} `json:"commits"`
+		}{}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, err
+		}
+		if p.Deleted || len(p.Commits) == 0 {
+			return nil, nil
+		}
+		commits := make([]commit, 0, len(p.Commits))
+		for _, c := range p.Commits {
+			commits = append(commits, commit{c.ID, c.Message, c.URL, c.Author.Name})
+		}
+		return &notification{
+			repo:    p.Repository.FullName,
+			content: pushMarkdown(p.Repository.FullName, p.Repository.HTMLURL, p.Ref, p.Pusher.Name, commits, len(commits)),
+		}, nil
+
+	case "pull_request":
+		p := struct {
+			Action      string `json:"action"`
+			Number      int    `json:"number"`
+			PullRequest struct {
+				Title   string     `json:"title"`
+				Body    string     `json:"body"`
+				HTMLURL string     `json:"html_url"`
+				Merged  bool       `json:"merged"`
+				User    githubUser `json:"user"`
+				Head    struct {
+					Ref string `json:"ref"`
+				} `json:"head"`
+				Base struct {
+					Ref string `json:"ref"`
+				} `json:"base"`
+			} `json:"pull_request"`
+			Repository githubRepo `json:"repository"`
+			Sender     githubUser `json:"sender"`
+		}{}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, err
+		}
+		action := p.Action
+		switch {
+		case action == "closed" && p.PullRequest.Merged:
+			action = "merged"
+		case action != "opened" && action != "closed" && action != "reopened":
+			return nil, nil
+		}
+		pr := p.PullRequest
+		return &notification{
+			repo: p.Repository.FullName,
+			article: &wecom.Article{
+				Title:       truncate(fmt.Sprintf("[%s] PR #%d %s: %s", p.Repository.FullName, p.Number, action, pr.Title), maxTitle),
+				Description: truncate(fmt.Sprintf("%s → %s by %s\n%s", pr.Head.Ref, pr.Base.Ref, p.Sender.Login, pr.Body), maxDescription),
+				URL:         pr.HTMLURL,
+			},
+		}, nil
+
+	case "workflow_run":
+		p := struct {
+			Action      string `json:"action"`
+			WorkflowRun struct {
+				Name       string     `json:"name"`
+				HeadBranch string     `json:"head_branch"`
+				Conclusion string     `json:"conclusion"`
+				HTMLURL    string     `json:"html_url"`
+				RunNumber  int        `json:"run_number"`
+				Actor      githubUser `json:"actor"`
+			} `json:"workflow_run"`
+			Repository githubRepo `json:"repository"`
+		}{}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, err
+		}
+		if p.Action != "completed" {
+			return nil, nil
+		}
+		run := p.WorkflowRun
+		return &notification{
+			repo:    p.Repository.FullName,
+			content: pipelineMarkdown(p.Repository.FullName, fmt.Sprintf("%s #%d", run.Name, run.RunNumber), run.HeadBranch, run.Conclusion, run.HTMLURL, run.Actor.Login),
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,155 @@
+package gitnotify
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+type GitLabHandler struct {
+	s      Sender
+	router Router
+	token  string
+}
+
+// token为webhook的Secret token，为空时不校验
+// 支持Push Hook、Merge Request Hook及Pipeline Hook，其余事件直接返回200
+func NewGitLabHandler(s Sender, router Router, token string) *GitLabHandler {
+	return &GitLabHandler{s: s, router: router, token: token}
+}
+
+type gitlabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+func (h *GitLabHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(rw)
+		return
+	}
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(h.token)) != 1 {
+		http.Error(rw, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, 5<<20))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n, err := gitlabNotification(r.Header.Get("X-Gitlab-Event"), body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if n != nil {
+		if err := send(r.Context(), h.s, h.router, n); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// 不需要推送的事件返回nil
+func gitlabNotification(event string, body []byte) (*notification, error) {
+	switch event {
+	case "Push Hook":
+		p := struct {
+			Ref               string        `json:"ref"`
+			UserName          string        `json:"user_name"`
+			TotalCommitsCount int           `json:"total_commits_count"`
+			Project           gitlabProject `json:"project"`
+			Commits           []struct {
+				ID      string `json:"id"`
+				Message string `json:"message"`
+				URL     string `json:"url"`
+				Author  struct {
+					Name string `json:"name"`
+				} `json:"author"`
+			} `json:"commits"`
+		}{}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, err
+		}
+		if p.TotalCommitsCount == 0 {
+			return nil, nil
+		}
+		commits := make([]commit, 0, len(p.Commits))
+		for _, c := range p.Commits {
+			commits = append(commits, commit{c.ID, c.Message, c.URL, c.Author.Name})
+		}
+		return &notification{
+			repo:    p.Project.PathWithNamespace,
+			content: pushMarkdown(p.Project.PathWithNamespace, p.Project.WebURL, p.Ref, p.UserName, commits, p.TotalCommitsCount),
+		}, nil
+
+	case "Merge Request Hook":
+		p := struct {
+			User struct {
+				Name string `json:"name"`
+			} `json:"user"`
+			Project          gitlabProject `json:"project"`
+			ObjectAttributes struct {
+				IID          int    `json:"iid"`
+				Title        string `json:"title"`
+				Description  string `json:"description"`
+				URL          string `json:"url"`
+				Action       string `json:"action"`
+				SourceBranch string `json:"source_branch"`
+				TargetBranch string `json:"target_branch"`
+			} `json:"object_attributes"`
+		}{}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, err
+		}
+		mr := p.ObjectAttributes
+		switch mr.Action {
+		case "open", "close", "reopen", "merge":
+		default:
+			return nil, nil
+		}
+		return &notification{
+			repo: p.Project.PathWithNamespace,
+			article: &wecom.Article{
+				Title:       truncate(fmt.Sprintf("[%s] MR !%d %s: %s", p.Project.PathWithNamespace, mr.IID, mr.Action, mr.Title), maxTitle),
+				Description: truncate(fmt.Sprintf("%s → %s by %s\n%s", mr.SourceBranch, mr.TargetBranch, p.User.Name, mr.Description), maxDescription),
+				URL:         mr.URL,
+			},
+		}, nil
+
+	case "Pipeline Hook":
+		p := struct {
+			User struct {
+				Name string `json:"name"`
+			} `json:"user"`
+			Project          gitlabProject `json:"project"`
+			ObjectAttributes struct {
+				ID     int    `json:"id"`
+				Ref    string `json:"ref"`
+				Status string `json:"status"`
+			} `json:"object_attributes"`
+		}{}
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, err
+		}
+		pl := p.ObjectAttributes
+		switch pl.Status {
+		case "success", "failed", "canceled":
+		default:
+			return nil, nil
+		}
+		id := strconv.Itoa(pl.ID)
+		return &notification{
+			repo:    p.Project.PathWithNamespace,
+			content: pipelineMarkdown(p.Project.PathWithNamespace, "Pipeline #"+id, pl.Ref, pl.Status, p.Project.WebURL+"/-/pipelines/"+id, p.User.Name),
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,115 @@
+// Package gitnotify 提供接收GitHub及GitLab webhook的http.Handler，将推送、PR/MR及流水线事件推送到企业微信
+package gitnotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+// Sender 由wecom.New返回的客户端实现
+type Sender interface {
+	Markdown(ctx context.Context, m *wecom.MarkdownInfo) (*wecom.SendResult, error)
+	News(ctx context.Context, n *wecom.NewsInfo) (*wecom.SendResult, error)
+}
+
+// Router 根据仓库全名（如owner/repo、group/project）返回接收者，ok为false时不推送
+type Router func(repo string) (r wecom.Recipients, ok bool)
+
+// 推送消息中最多列出的提交数
+const maxCommits = 5
+
+// 仓库无关的事件，由各平台的payload转换而来
+type notification struct {
+	repo string
+	// 为nil时以markdown发送
+	article *wecom.Article
+	content string
+}
+
+func send(ctx context.Context, s Sender, router Router, n *notification) error {
+	rcpt, ok := router(n.repo)
+	if !ok {
+		return nil
+	}
+	if n.article != nil {
+		_, err := s.News(ctx, &wecom.NewsInfo{
+			Touser:   rcpt.Touser,
+			Toparty:  rcpt.Toparty,
+			Totag:    rcpt.Totag,
+			AgentID:  rcpt.AgentID,
+			Articles: []wecom.Article{*n.article},
+		})
+		return err
+	}
+	_, err := s.Markdown(ctx, &wecom.MarkdownInfo{
+		Touser:  rcpt.Touser,
+		Toparty: rcpt.Toparty,
+		Totag:   rcpt.Totag,
+		AgentID: rcpt.AgentID,
+		Content: n.content,
+	})
+	return err
+}
+
+type commit struct {
+	id, message, url, author string
+}
+
+// markdown消息内容最长4096字节，单个提交的标题最多显示maxCommitMessage字节
+const (
+	maxMarkdownBytes = 4096
+	maxCommitMessage = 256
+	// 为“……另有N个提交”预留的长度
+	omittedReserve = 64
+)
+
+func pushMarkdown(repo, repoURL, ref, pusher string, commits []commit, total int) string {
+	var b strings.Builder
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+	fmt.Fprintf(&b, "**%s** 推送了 %d 个提交到 [%s](%s) 的 `%s`\n", pusher, total, repo, repoURL, branch)
+	for i, c := range commits {
+		msg, _, _ := strings.Cut(c.message, "\n")
+		id := c.id
+		if len(id) > 8 {
+			id = id[:8]
+		}
+		line := fmt.Sprintf("> [%s](%s) %s - %s\n", id, c.url, truncate(msg, maxCommitMessage), c.author)
+		if i == maxCommits || b.Len()+len(line) > maxMarkdownBytes-omittedReserve {
+			fmt.Fprintf(&b, "> ……另有%d个提交\n", total-i)
+			break
+		}
+		b.WriteString(line)
+	}
+	return truncate(strings.TrimRight(b.String(), "\n"), maxMarkdownBytes)
+}
+
+func pipelineMarkdown(repo, name, ref, status, url, actor string) string {
+	color := "warning"
+	if status == "success" {
+		color = "info"
+	}
+	return fmt.Sprintf("**%s** %s\n> 分支：`%s`\n> 状态：<font color=\"%s\">%s</font>\n> 触发者：%s\n[查看详情](%s)",
+		repo, name, ref, color, status, actor, url)
+}
+
+// 图文消息的标题最长128字节，描述最长512字节
+const (
+	maxTitle       = 128
+	maxDescription = 512
+)
+
+// 截断为不超过n字节
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return wecom.TruncateToBytes(s, n-len("…")) + "…"
+}
+
+func methodNotAllowed(rw http.ResponseWriter) {
+	http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,327 @@
+// Package grafana 提供接收Grafana统一告警webhook的http.Handler，将告警以模板卡片推送，附带面板链接及截图
+package grafana
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jzksnsjswkw/wecom-push"
+	"github.com/jzksnsjswkw/wecom-push/internal/route"
+)
+
+// Grafana webhook的请求体
+type Message struct {
+	Receiver          string            `json:"receiver"`
+	Status            string            `json:"status"`
+	OrgID             int64             `json:"orgId"`
+	Alerts            []Alert           `json:"alerts"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+	Title             string            `json:"title"`
+	State             string            `json:"state"`
+	Message           string            `json:"message"`
+}
+
+type Alert struct {
+	// firing或resolved
+	Status       string             `json:"status"`
+	Labels       map[string]string  `json:"labels"`
+	Annotations  map[string]string  `json:"annotations"`
+	StartsAt     time.Time          `json:"startsAt"`
+	EndsAt       time.Time          `json:"endsAt"`
+	GeneratorURL string             `json:"generatorURL"`
+	Fingerprint  string             `json:"fingerprint"`
+	SilenceURL   string             `json:"silenceURL"`
+	DashboardURL string             `json:"dashboardURL"`
+	PanelURL     string             `json:"panelURL"`
+	Values       map[string]float64 `json:"values"`
+	ValueString  string             `json:"valueString"`
+	// 告警截图，需在Grafana中开启image rendering
+	ImageURL string `json:"imageURL"`
+}
+
+// Sender 由wecom.New返回的客户端实现
+type Sender interface {
+	Markdown(ctx context.Context, m *wecom.MarkdownInfo) (*wecom.SendResult, error)
+	TemplateCard(ctx context.Context, t *wecom.TemplateCardInfo) (*wecom.SendResult, error)
+	UploadImage(ctx context.Context, content []byte, filename string) (string, error)
+}
+
+// Router 返回告警的接收者，ok为false时不推送该告警
+type Router func(a *Alert) (r wecom.Recipients, ok bool)
+
+// 所有告警均推送给r
+func Static(r wecom.Recipients) Router {
+	return func(*Alert) (wecom.Recipients, bool) {
+		return r, true
+	}
+}
+
+// 根据标签label的值选择接收者，没有对应的值时使用fallback，fallback为零值时不推送
+func LabelRouter(label string, routes map[string]wecom.Recipients, fallback wecom.Recipients) Router {
+	return func(a *Alert) (wecom.Recipients, bool) {
+		return route.ByLabel(a.Labels, label, routes, fallback)
+	}
+}
+
+type Handler struct {
+	s      Sender
+	router Router
+	token  string
+	// 只下载该地址下的告警截图，为nil时不下载
+	grafanaURL *url.URL
+	// 用于下载告警截图
+	httpClient *http.Client
+}
+
+// 告警截图通过http.DefaultClient下载，可通过SetHTTPClient修改；需先通过SetGrafanaURL设置Grafana的地址，否则不下载截图
+func NewHandler(s Sender, router Router) *Handler {
+	return &Handler{s: s, router: router, httpClient: http.DefaultClient}
+}
+
+// 设置下载告警截图使用的http.Client，如需携带Grafana的认证信息
+func (h *Handler) SetHTTPClient(c *http.Client) {
+	h.httpClient = c
+}
+
+// 请求需携带Authorization: Bearer <token>，对应Grafana联络点的Authorization Header，为空时不校验
+func (h *Handler) SetToken(token string) {
+	h.token = token
+}
+
+// 设置Grafana的地址（如https://grafana.example.com/），只下载该地址下的告警截图，避免请求体中的任意地址被访问
+func (h *Handler) SetGrafanaURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("grafana: invalid grafana url %q", rawURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+	h.grafanaURL = u
+	return nil
+}
+
+// u是否位于grafanaURL下
+func (h *Handler) allowImage(u *url.URL) bool {
+	g := h.grafanaURL
+	return g != nil && u.Scheme == g.Scheme && u.Host == g.Host && strings.HasPrefix(path.Clean("/"+u.Path), g.Path)
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if h.token != "" {
+		got, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+			http.Error(rw, "invalid token", http.StatusUnauthorized)
+			return
+		}
+	}
+	m := &Message{}
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, 1<<20)).Decode(m); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.Notify(r.Context(), m); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadGateway)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// 每条告警推送一张模板卡片，有截图时为图文展示型，否则为文本通知型
+// 告警没有任何可跳转的地址时，卡片缺少必填的card_action，改为推送markdown消息
+func (h *Handler) Notify(ctx context.Context, m *Message) error {
+	var errs []error
+	for i := range m.Alerts {
+		a := &m.Alerts[i]
+		rcpt, ok := h.router(a)
+		if !ok {
+			continue
+		}
+		var err error
+		if u := cardURL(m, a); u != "" {
+			_, err = h.s.TemplateCard(ctx, &wecom.TemplateCardInfo{
+				Touser:  rcpt.Touser,
+				Toparty: rcpt.Toparty,
+				Totag:   rcpt.Totag,
+				AgentID: rcpt.AgentID,
+				Card:    h.card(ctx, a, u),
+			})
+		} else {
+			_, err = h.s.Markdown(ctx, &wecom.MarkdownInfo{
+				Touser:  rcpt.Touser,
+				Toparty: rcpt.Toparty,
+				Totag:   rcpt.Totag,
+				AgentID: rcpt.AgentID,
+				Content: markdown(a),
+			})
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 点击卡片跳转的地址，依次使用面板、仪表盘、告警规则及Grafana的地址
+func cardURL(m *Message, a *Alert) string {
+	for _, u := range []string{a.PanelURL, a.DashboardURL, a.GeneratorURL, m.ExternalURL} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+func title(a *Alert) string {
+	status := "FIRING"
+	if a.Status == "resolved" {
+		status = "RESOLVED"
+	}
+	return fmt.Sprintf("[%s] %s", status, a.Labels["alertname"])
+}
+
+func summary(a *Alert) string {
+	if s := a.Annotations["summary"]; s != "" {
+		return s
+	}
+	return a.Annotations["description"]
+}
+
+func valueKeys(a *Alert) []string {
+	keys := make([]string, 0, len(a.Values))
+	for k := range a.Values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (h *Handler) card(ctx context.Context, a *Alert, u string) *wecom.TemplateCard {
+	color := 2
+	if a.Status == "resolved" {
+		color = 3
+	}
+	card := &wecom.TemplateCard{
+		CardType:   wecom.TextNotice,
+		Source:     &wecom.CardSource{Desc: "Grafana", DescColor: color},
+		MainTitle:  &wecom.CardMainTitle{Title: title(a), Desc: summary(a)},
+		CardAction: &wecom.CardAction{Type: 1, URL: u},
+	}
+
+	for _, k := range valueKeys(a) {
+		if len(card.HorizontalContentList) == 5 {
+			break
+		}
+		card.HorizontalContentList = append(card.HorizontalContentList, wecom.CardHorizontalContent{
+			Keyname: k,
+			Value:   fmt.Sprintf("%g", a.Values[k]),
+		})
+	}
+	card.HorizontalContentList = append(card.HorizontalContentList, wecom.CardHorizontalContent{
+		Keyname: "开始",
+		Value:   a.StartsAt.Local().Format(time.DateTime),
+	})
+
+	for _, j := range []struct{ title, url string }{
+		{"查看面板", a.PanelURL},
+		{"查看仪表盘", a.DashboardURL},
+		{"静默告警", a.SilenceURL},
+	} {
+		if j.url != "" {
+			card.JumpList = append(card.JumpList, wecom.CardJump{Type: 1, Title: j.title, URL: j.url})
+		}
+	}
+
+	if a.ImageURL != "" {
+		// 截图上传失败时退化为文本通知型卡片
+		if img, err := h.uploadImage(ctx, a.ImageURL); err == nil {
+			card.CardType = wecom.NewsNotice
+			card.CardImage = &wecom.CardImage{URL: img}
+		}
+	}
+	return card
+}
+
+// markdown消息内容最长4096字节
+const maxMarkdownBytes = 4096
+
+func markdown(a *Alert) string {
+	var b strings.Builder
+	color := "warning"
+	if a.Status == "resolved" {
+		color = "info"
+	}
+	fmt.Fprintf(&b, "<font color=\"%s\">**%s**</font>\n", color, title(a))
+	if s := summary(a); s != "" {
+		fmt.Fprintf(&b, "%s\n", s)
+	}
+	for _, k := range valueKeys(a) {
+		fmt.Fprintf(&b, "> %s：%g\n", k, a.Values[k])
+	}
+	fmt.Fprintf(&b, "> 开始：%s", a.StartsAt.Local().Format(time.DateTime))
+	if a.SilenceURL != "" {
+		fmt.Fprintf(&b, "\n[静默告警](%s)", a.SilenceURL)
+	}
+	return wecom.TruncateToBytes(b.String(), maxMarkdownBytes)
+}
+
+var errImageNotAllowed = errors.New("grafana: image url is not under the grafana url")
+
+// 下载Grafana的截图后通过uploadimg上传，返回企业微信的图片地址
+// 截图地址及重定向后的地址均须位于SetGrafanaURL设置的地址下
+func (h *Handler) uploadImage(ctx context.Context, imageURL string) (string, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if !h.allowImage(r.URL) {
+		return "", errImageNotAllowed
+	}
+	c := *h.httpClient
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !h.allowImage(req.URL) {
+			return errImageNotAllowed
+		}
+		if h.httpClient.CheckRedirect != nil {
+			return h.httpClient.CheckRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("grafana: stopped after 10 redirects")
+		}
+		return nil
+	}
+	resp, err := c.Do(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("grafana: download image: %s", resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20+1))
+	if err != nil {
+		return "", err
+	}
+	return h.s.UploadImage(ctx, b, "grafana.png")
+}
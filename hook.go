@@ -0,0 +1,33 @@
+package wecom
+
+import (
+	"net/http"
+	"time"
+)
+
+// 请求发出前调用，可用于注入tracing header等，请勿读取请求体
+type RequestHook func(r *http.Request)
+
+type Response struct {
+	Request    *http.Request
+	StatusCode int
+	Body       []byte
+	Latency    time.Duration
+}
+
+// 请求完成后调用，网络错误时resp.StatusCode为0
+type ResponseHook func(resp *Response, err error)
+
+// 可多次调用，按添加顺序执行
+func WithRequestHook(h RequestHook) Option {
+	return func(w *Wecom) {
+		w.requestHooks = append(w.requestHooks, h)
+	}
+}
+
+// 可多次调用，按添加顺序执行
+func WithResponseHook(h ResponseHook) Option {
+	return func(w *Wecom) {
+		w.responseHooks = append(w.responseHooks, h)
+	}
+}
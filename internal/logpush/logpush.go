@@ -0,0 +1,70 @@
+// Package logpush 提供wecomslog、wecomlogrus、wecomzap等日志适配包共用的推送频率限制及内容截断
+package logpush
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+// 默认每分钟最多推送的条数
+const DefaultPerMinute = 10
+
+// Limiter 限制每分钟推送的条数，超出的日志被丢弃，丢弃的数量在下一条推送中注明
+type Limiter struct {
+	limiter *rate.Limiter
+	lock    sync.Mutex
+	dropped int
+}
+
+// perMinute小于等于0时使用DefaultPerMinute
+func NewLimiter(perMinute int) *Limiter {
+	if perMinute <= 0 {
+		perMinute = DefaultPerMinute
+	}
+	return &Limiter{limiter: rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), perMinute)}
+}
+
+// 返回是否推送，推送时同时返回此前被丢弃的数量并清零
+func (l *Limiter) Allow() (bool, int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if !l.limiter.Allow() {
+		l.dropped++
+		return false, 0
+	}
+	dropped := l.dropped
+	l.dropped = 0
+	return true, dropped
+}
+
+// 因其他原因（如队列已满）未推送时调用，计入丢弃的数量
+func (l *Limiter) Drop() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.dropped++
+}
+
+// 追加在推送内容末尾，dropped为0时返回空字符串
+func Footer(dropped int) string {
+	if dropped == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n（此前有%d条日志因频率限制未推送）", dropped)
+}
+
+// markdown消息内容最长4096字节
+const maxContentBytes = 4096
+
+// 将s截断到消息的长度限制内并追加Footer，避免过长的日志（如堆栈）推送失败而被丢弃
+func Content(s string, dropped int) string {
+	footer := Footer(dropped)
+	if len(s)+len(footer) > maxContentBytes {
+		s = wecom.TruncateToBytes(s, maxContentBytes-len(footer)-len("…")) + "…"
+	}
+	return s + footer
+}
@@ -0,0 +1,12 @@
+// Package route 提供alertmanager、grafana等告警接收包共用的接收者路由逻辑
+package route
+
+import "github.com/jzksnsjswkw/wecom-push"
+
+// 根据标签label的值选择接收者，没有对应的值时使用fallback，fallback为零值时ok为false
+func ByLabel(labels map[string]string, label string, routes map[string]wecom.Recipients, fallback wecom.Recipients) (wecom.Recipients, bool) {
+	if r, ok := routes[labels[label]]; ok {
+		return r, true
+	}
+	return fallback, fallback != wecom.Recipients{}
+}
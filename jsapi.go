@@ -0,0 +1,83 @@
+package wecom
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type jsapiTicket struct {
+	lock      sync.RWMutex
+	ticket    string
+	expiresAt time.Time
+}
+
+// 返回未过期的企业jsapi_ticket，有效期与access_token相同，同样提前刷新
+func (w *Wecom) JSAPITicket(ctx context.Context) (string, error) {
+	w.jsapiTicket.lock.RLock()
+	ticket, expiresAt := w.jsapiTicket.ticket, w.jsapiTicket.expiresAt
+	w.jsapiTicket.lock.RUnlock()
+	if ticket != "" && time.Now().Before(expiresAt) {
+		return ticket, nil
+	}
+
+	v, err, _ := w.refreshGroup.Do("jsapi_ticket", func() (any, error) {
+		b, err := w.get(ctx, "get_jsapi_ticket", nil)
+		if err != nil {
+			return nil, err
+		}
+		r := struct {
+			Ticket    string `json:"ticket"`
+			ExpiresIn int    `json:"expires_in"`
+		}{}
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, err
+		}
+		w.jsapiTicket.lock.Lock()
+		w.jsapiTicket.ticket = r.Ticket
+		w.jsapiTicket.expiresAt = time.Now().Add(time.Duration(r.ExpiresIn)*time.Second - tokenRefreshMargin)
+		w.jsapiTicket.lock.Unlock()
+		return r.Ticket, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// wx.config所需的参数
+type JSConfig struct {
+	AppID     string `json:"appId"`
+	Timestamp int64  `json:"timestamp"`
+	NonceStr  string `json:"nonceStr"`
+	Signature string `json:"signature"`
+}
+
+// 计算当前网页调用wx.config的签名，pageURL为调用JS接口页面的完整URL，#及其后的部分不参与签名
+func (w *Wecom) ConfigSignature(ctx context.Context, pageURL string) (*JSConfig, error) {
+	ticket, err := w.JSAPITicket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	c := &JSConfig{
+		AppID:     w.corpid,
+		Timestamp: time.Now().Unix(),
+		NonceStr:  hex.EncodeToString(nonce),
+	}
+	pageURL, _, _ = strings.Cut(pageURL, "#")
+	s := "jsapi_ticket=" + ticket + "&noncestr=" + c.NonceStr +
+		"&timestamp=" + strconv.FormatInt(c.Timestamp, 10) + "&url=" + pageURL
+	h := sha1.Sum([]byte(s))
+	c.Signature = hex.EncodeToString(h[:])
+	return c, nil
+}
@@ -0,0 +1,64 @@
+package wecom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+type LinkedCorpMessage struct {
+	// 本企业成员直接填写userid，互联企业成员填写“CorpId/userid”
+	Touser []string
+	// 本企业部门直接填写部门id，互联企业部门填写“LinkedId/DepartmentId”
+	Toparty []string
+	Totag   []string
+	// 发送给应用可见范围内的所有人（包括互联企业的成员），需先调用AllowToAll(true)
+	ToAll   bool
+	AgentID int
+	// 支持text、image、voice、video、file、textcard、news、mpnews、markdown、miniprogram_notice
+	Msgtype string
+	// 对应消息类型的内容，如text为map[string]string{"content": "..."}
+	Content any
+	Safe    bool
+}
+
+type LinkedCorpResult struct {
+	InvalidUser  []string `json:"invaliduser"`
+	InvalidParty []string `json:"invalidparty"`
+	InvalidTag   []string `json:"invalidtag"`
+}
+
+// 向互联企业的成员发送应用消息
+func (w *Wecom) LinkedCorpSend(ctx context.Context, m *LinkedCorpMessage) (*LinkedCorpResult, error) {
+	if m.Msgtype == "" {
+		return nil, errors.New("linkedcorp message requires msgtype")
+	}
+	if m.ToAll && !w.allowToAll {
+		return nil, ErrToAllNotAllowed
+	}
+	d := map[string]any{
+		"touser":  m.Touser,
+		"toparty": m.Toparty,
+		"totag":   m.Totag,
+		"agentid": w.agent(m.AgentID),
+		"msgtype": m.Msgtype,
+		m.Msgtype: m.Content,
+	}
+	if m.ToAll {
+		d["toall"] = 1
+	}
+	if m.Safe {
+		d["safe"] = 1
+	}
+	ctx = WithAgentContext(ctx, w.agent(m.AgentID))
+	b, err := w.post(withMsgtype(ctx, m.Msgtype), "linkedcorp/message/send", d)
+	w.observeMessage(m.Msgtype, err)
+	if err != nil {
+		return nil, err
+	}
+	r := &LinkedCorpResult{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,95 @@
+package wecom
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 库内部的诊断日志，*log.Logger即满足该接口
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// 设置诊断日志输出，默认不输出
+func WithLogger(l Logger) Option {
+	return func(w *Wecom) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		w.logger = l
+	}
+}
+
+type slogLogger struct {
+	l     *slog.Logger
+	level slog.Level
+}
+
+func (s slogLogger) Printf(format string, v ...any) {
+	s.l.Log(context.Background(), s.level, fmt.Sprintf(format, v...))
+}
+
+// 将诊断日志以指定级别输出到slog
+func SlogLogger(l *slog.Logger, level slog.Level) Logger {
+	return slogLogger{l: l, level: level}
+}
+
+// 以Debug级别记录每次接口调用的地址、错误码、耗时及截断后的请求和响应内容
+func WithSlog(l *slog.Logger) Option {
+	return func(w *Wecom) {
+		w.slog = l
+	}
+}
+
+const maxLoggedPayload = 512
+
+func truncatePayload(b []byte) string {
+	if len(b) > maxLoggedPayload {
+		return TruncateToBytes(string(b), maxLoggedPayload) + "...(truncated)"
+	}
+	return string(b)
+}
+
+func requestPayload(r *http.Request) string {
+	if r.GetBody == nil || !strings.HasPrefix(r.Header.Get("content-type"), "application/json") {
+		return ""
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	b, _ := io.ReadAll(io.LimitReader(body, maxLoggedPayload+1))
+	return truncatePayload(b)
+}
+
+func (w *Wecom) logCall(r *http.Request, resp []byte, latency time.Duration, err error) {
+	if w.slog == nil || !w.slog.Enabled(r.Context(), slog.LevelDebug) {
+		return
+	}
+	attrs := []any{
+		slog.String("endpoint", endpoint(r)),
+		slog.Duration("latency", latency),
+	}
+	if p := requestPayload(r); p != "" {
+		attrs = append(attrs, slog.String("request", p))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	} else {
+		if errcode, _, ok := errcodeOf(resp); ok {
+			attrs = append(attrs, slog.Int("errcode", errcode))
+		}
+		attrs = append(attrs, slog.String("response", truncatePayload(resp)))
+	}
+	w.slog.DebugContext(r.Context(), "wecom api call", attrs...)
+}
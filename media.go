@@ -0,0 +1,221 @@
+package wecom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type Media struct {
+	Content     []byte
+	ContentType string
+	Filename    string
+}
+
+// 成功时响应为文件内容，失败时为包含errcode的JSON
+func (w *Wecom) download(ctx context.Context, path, mediaID string) (*Media, error) {
+	var m *Media
+	buf := func(token string) ([]byte, error) {
+		q := url.Values{
+			"access_token": {token},
+			"media_id":     {mediaID},
+		}
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, w.baseURL+path+"?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		h, b, err := w.doHeader(r)
+		if err != nil {
+			return nil, err
+		}
+		ct := h.Get("content-type")
+		if strings.HasPrefix(ct, "application/json") || strings.HasPrefix(ct, "text/plain") {
+			return b, nil
+		}
+		m = &Media{Content: b, ContentType: ct}
+		if _, params, err := mime.ParseMediaType(h.Get("content-disposition")); err == nil {
+			m.Filename = params["filename"]
+		}
+		return []byte(`{"errcode":0}`), nil
+	}
+	if _, err := w.send(ctx, buf); err != nil {
+		return nil, err
+	}
+	// 演练模式下没有文件内容
+	if m == nil {
+		m = &Media{}
+	}
+	return m, nil
+}
+
+// 获取临时素材，可用于下载回调消息中成员发送的图片、语音、视频及文件
+func (w *Wecom) GetMedia(ctx context.Context, mediaID string) (*Media, error) {
+	return w.download(ctx, "media/get", mediaID)
+}
+
+// 获取高清语音素材，返回speex格式（16K采样率）的语音文件，mediaID为JS-SDK上传语音时返回的serverId
+func (w *Wecom) GetHDVoice(ctx context.Context, mediaID string) (*Media, error) {
+	return w.download(ctx, "media/get/jssdk", mediaID)
+}
+
+// 上传图片得到永久有效的URL，可用于图文消息及markdown中，图片大小为5B~2MB，仅支持JPG、PNG格式
+func (w *Wecom) UploadImage(ctx context.Context, content []byte, filename string) (string, error) {
+	if len(content) < 5 || len(content) > 2<<20 {
+		return "", errors.New("image size must be between 5B and 2MB")
+	}
+	if ct := http.DetectContentType(content); ct != "image/jpeg" && ct != "image/png" {
+		return "", errors.New("image must be jpg or png")
+	}
+
+	buf := func(token string) ([]byte, error) {
+		u := w.baseURL + "media/uploadimg?access_token=" + url.QueryEscape(token)
+		return w.upload(ctx, u, content, filename)
+	}
+	b, err := w.send(ctx, buf)
+	if err != nil {
+		return "", err
+	}
+	r := struct {
+		URL string `json:"url"`
+	}{}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return "", err
+	}
+	return r.URL, nil
+}
+
+// 各类型临时素材的大小上限，所有文件均不能小于5字节
+var mediaSizeLimits = map[Filetype]int64{
+	IMAGE: 10 << 20,
+	VOICE: 2 << 20,
+	VIDEO: 10 << 20,
+	FILE:  20 << 20,
+}
+
+// 上传前校验素材大小及格式，head为文件开头的内容（至少512字节，文件较小时为全部内容）
+func validateMedia(filetype Filetype, size int64, head []byte) error {
+	limit, ok := mediaSizeLimits[filetype]
+	if !ok {
+		return fmt.Errorf("unsupported media type %q", filetype)
+	}
+	if size < 5 {
+		return fmt.Errorf("%s size %d bytes is less than 5 bytes", filetype, size)
+	}
+	if size > limit {
+		return fmt.Errorf("%s size %d bytes exceeds limit of %dMB", filetype, size, limit>>20)
+	}
+
+	switch filetype {
+	case IMAGE:
+		if ct := http.DetectContentType(head); ct != "image/jpeg" && ct != "image/png" {
+			return fmt.Errorf("image must be jpg or png, got %s", ct)
+		}
+	case VOICE:
+		if !bytes.HasPrefix(head, []byte("#!AMR\n")) {
+			return errors.New("voice must be amr format")
+		}
+	case VIDEO:
+		if len(head) < 8 || string(head[4:8]) != "ftyp" {
+			return errors.New("video must be mp4 format")
+		}
+	}
+	return nil
+}
+
+// 以流的方式构造multipart请求体，避免将大文件整体读入内存
+func multipartStream(r io.Reader, size int64, filename string) (body io.Reader, length int64, contentType string, err error) {
+	b := &bytes.Buffer{}
+	writer := multipart.NewWriter(b)
+	if _, err := writer.CreateFormFile("media", filename); err != nil {
+		return nil, 0, "", err
+	}
+	n := b.Len()
+	if err := writer.Close(); err != nil {
+		return nil, 0, "", err
+	}
+	head, tail := b.Bytes()[:n], b.Bytes()[n:]
+	body = io.MultiReader(bytes.NewReader(head), io.LimitReader(r, size), bytes.NewReader(tail))
+	return body, int64(len(head)) + size + int64(len(tail)), writer.FormDataContentType(), nil
+}
+
+// 上传临时素材，r的内容长度须为size，返回的media_id 3天内有效
+// access_token失效需要重新上传时，r须实现io.Seeker，否则返回错误
+func (w *Wecom) UploadMedia(ctx context.Context, r io.Reader, size int64, filetype Filetype, filename string) (string, error) {
+	var start int64
+	seeker, seekable := r.(io.Seeker)
+	if seekable {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", err
+		}
+		start = offset
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	head = head[:n]
+	if err := validateMedia(filetype, size, head); err != nil {
+		return "", err
+	}
+	if w.dryRun {
+		w.logDryRun("media/upload type=%s filename=%s size=%d", filetype, filename, size)
+		return dryRunMediaID, nil
+	}
+	if seekable {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return "", err
+		}
+	} else {
+		r = io.MultiReader(bytes.NewReader(head), r)
+	}
+
+	attempts := 0
+	buf := func(token string) ([]byte, error) {
+		if attempts > 0 {
+			if !seekable {
+				return nil, errors.New("cannot retry upload from a non-seekable reader")
+			}
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		attempts++
+
+		body, length, contentType, err := multipartStream(r, size, filename)
+		if err != nil {
+			return nil, err
+		}
+		u := fmt.Sprintf("%vmedia/upload?access_token=%v&type=%v", w.baseURL, url.QueryEscape(token), filetype)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = length
+		req.Header.Add("content-type", contentType)
+		req.Header.Add("accept", "application/json")
+		return w.do(req)
+	}
+
+	b, err := w.send(ctx, buf)
+	if err != nil {
+		return "", err
+	}
+	m := struct {
+		MediaID string `json:"media_id"`
+	}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", err
+	}
+	return m.MediaID, nil
+}
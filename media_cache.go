@@ -0,0 +1,69 @@
+package wecom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// 临时素材media_id的有效期为3天，提前1小时失效避免发送时恰好过期
+const defaultMediaCacheTTL = 3*24*time.Hour - time.Hour
+
+type mediaCacheEntry struct {
+	mediaID   string
+	expiresAt time.Time
+}
+
+type mediaCache struct {
+	lock    sync.Mutex
+	ttl     time.Duration
+	entries map[string]mediaCacheEntry
+}
+
+// 缓存已上传素材的media_id，相同内容、类型及文件名的素材在ttl内不再重复上传
+// ttl小于等于0或超过3天时使用默认值（3天减1小时），仅对[]byte内容生效，UploadMedia等流式上传不缓存
+func WithMediaCache(ttl time.Duration) Option {
+	return func(w *Wecom) {
+		if ttl <= 0 || ttl > defaultMediaCacheTTL {
+			ttl = defaultMediaCacheTTL
+		}
+		w.mediaCache = &mediaCache{ttl: ttl, entries: map[string]mediaCacheEntry{}}
+	}
+}
+
+func mediaCacheKey(content []byte, filetype Filetype, filename string) string {
+	h := sha256.New()
+	h.Write(content)
+	h.Write([]byte{0})
+	h.Write([]byte(filetype))
+	h.Write([]byte{0})
+	h.Write([]byte(filename))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *mediaCache) get(key string) (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !time.Now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return e.mediaID, true
+}
+
+func (c *mediaCache) set(key, mediaID string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = mediaCacheEntry{mediaID: mediaID, expiresAt: now.Add(c.ttl)}
+}
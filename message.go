@@ -0,0 +1,437 @@
+package wecom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type MarkdownInfo struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+	// 支持标题、加粗、链接、引用、字体颜色等markdown子集
+	Content string
+
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) Markdown(ctx context.Context, m *MarkdownInfo) (*SendResult, error) {
+	msg, body := markdownMessage(m)
+	return w.sendMessage(ctx, msg, "markdown", body)
+}
+
+func markdownMessage(m *MarkdownInfo) (*message, any) {
+	return &message{
+		Touser:                 m.Touser,
+		Toparty:                m.Toparty,
+		Totag:                  m.Totag,
+		AgentID:                m.AgentID,
+		EnableDuplicateCheck:   m.EnableDuplicateCheck,
+		DuplicateCheckInterval: m.DuplicateCheckInterval,
+	}, map[string]string{
+		"content": m.Content,
+	}
+}
+
+type TextCardInfo struct {
+	Touser      string
+	Toparty     string
+	Totag       string
+	AgentID     int
+	Safe        bool
+	Title       string
+	Description string
+	URL         string
+	// 按钮文字，默认为“详情”
+	Btntxt string
+
+	EnableIDTrans          bool
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) TextCard(ctx context.Context, t *TextCardInfo) (*SendResult, error) {
+	m, body := textCardMessage(t)
+	return w.sendMessage(ctx, m, "textcard", body)
+}
+
+func textCardMessage(t *TextCardInfo) (*message, any) {
+	card := map[string]string{
+		"title":       t.Title,
+		"description": t.Description,
+		"url":         t.URL,
+	}
+	if t.Btntxt != "" {
+		card["btntxt"] = t.Btntxt
+	}
+	return &message{
+		Touser:                 t.Touser,
+		Toparty:                t.Toparty,
+		Totag:                  t.Totag,
+		AgentID:                t.AgentID,
+		Safe:                   t.Safe,
+		EnableIDTrans:          t.EnableIDTrans,
+		EnableDuplicateCheck:   t.EnableDuplicateCheck,
+		DuplicateCheckInterval: t.DuplicateCheckInterval,
+	}, card
+}
+
+type Article struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+	PicURL      string `json:"picurl,omitempty"`
+	// 小程序appid，设置后点击跳转小程序，此时URL无效
+	Appid    string `json:"appid,omitempty"`
+	Pagepath string `json:"pagepath,omitempty"`
+}
+
+type NewsInfo struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+	// 1~8条
+	Articles []Article
+
+	EnableIDTrans          bool
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) News(ctx context.Context, n *NewsInfo) (*SendResult, error) {
+	m, body, err := newsMessage(n)
+	if err != nil {
+		return nil, err
+	}
+	return w.sendMessage(ctx, m, "news", body)
+}
+
+func newsMessage(n *NewsInfo) (*message, any, error) {
+	if len(n.Articles) == 0 || len(n.Articles) > 8 {
+		return nil, nil, errors.New("news articles count must be between 1 and 8")
+	}
+	return &message{
+		Touser:                 n.Touser,
+		Toparty:                n.Toparty,
+		Totag:                  n.Totag,
+		AgentID:                n.AgentID,
+		EnableIDTrans:          n.EnableIDTrans,
+		EnableDuplicateCheck:   n.EnableDuplicateCheck,
+		DuplicateCheckInterval: n.DuplicateCheckInterval,
+	}, map[string]any{
+		"articles": n.Articles,
+	}, nil
+}
+
+type MPArticle struct {
+	Title string
+	// 缩略图内容，发送前自动上传获取thumb_media_id
+	Thumb         []byte
+	ThumbFilename string
+	Author        string
+	// 点击“阅读原文”之后的页面链接
+	ContentSourceURL string
+	// 支持html标签
+	Content string
+	Digest  string
+}
+
+type MPNewsInfo struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+	Safe    bool
+	// 1~8条
+	Articles []MPArticle
+
+	EnableIDTrans          bool
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) MPNews(ctx context.Context, n *MPNewsInfo) (*SendResult, error) {
+	if len(n.Articles) == 0 || len(n.Articles) > 8 {
+		return nil, errors.New("mpnews articles count must be between 1 and 8")
+	}
+
+	articles := make([]map[string]string, 0, len(n.Articles))
+	for _, a := range n.Articles {
+		filename := a.ThumbFilename
+		if filename == "" {
+			filename = "thumb.jpg"
+		}
+		m, err := w.getMediaID(ctx, a.Thumb, IMAGE, filename)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, map[string]string{
+			"title":              a.Title,
+			"thumb_media_id":     m,
+			"author":             a.Author,
+			"content_source_url": a.ContentSourceURL,
+			"content":            a.Content,
+			"digest":             a.Digest,
+		})
+	}
+
+	return w.sendMessage(ctx, &message{
+		Touser:                 n.Touser,
+		Toparty:                n.Toparty,
+		Totag:                  n.Totag,
+		AgentID:                n.AgentID,
+		Safe:                   n.Safe,
+		EnableIDTrans:          n.EnableIDTrans,
+		EnableDuplicateCheck:   n.EnableDuplicateCheck,
+		DuplicateCheckInterval: n.DuplicateCheckInterval,
+	}, "mpnews", map[string]any{
+		"articles": articles,
+	})
+}
+
+type MiniprogramContentItem struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type MiniprogramNoticeInfo struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	// 小程序appid，必须是与当前应用关联的小程序
+	Appid string
+	// 点击消息卡片后的小程序页面，仅限本小程序内的页面
+	Page        string
+	Title       string
+	Description string
+	// 是否放大第一个content_item
+	EmphasisFirstItem bool
+	// 最多10个
+	ContentItem []MiniprogramContentItem
+
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) MiniprogramNotice(ctx context.Context, m *MiniprogramNoticeInfo) (*SendResult, error) {
+	msg, body, err := miniprogramNoticeMessage(m)
+	if err != nil {
+		return nil, err
+	}
+	return w.sendMessage(ctx, msg, "miniprogram_notice", body)
+}
+
+func miniprogramNoticeMessage(m *MiniprogramNoticeInfo) (*message, any, error) {
+	if len(m.ContentItem) > 10 {
+		return nil, nil, errors.New("miniprogram_notice content_item count must not exceed 10")
+	}
+	return &message{
+		Touser:                 m.Touser,
+		Toparty:                m.Toparty,
+		Totag:                  m.Totag,
+		EnableDuplicateCheck:   m.EnableDuplicateCheck,
+		DuplicateCheckInterval: m.DuplicateCheckInterval,
+	}, map[string]any{
+		"appid":               m.Appid,
+		"page":                m.Page,
+		"title":               m.Title,
+		"description":         m.Description,
+		"emphasis_first_item": m.EmphasisFirstItem,
+		"content_item":        m.ContentItem,
+	}, nil
+}
+
+type ImageInfo struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+	Safe    bool
+	// 图片大小不超过10MB，支持JPG、PNG格式
+	Content []byte
+
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) Image(ctx context.Context, i *ImageInfo) (*SendResult, error) {
+	var filename string
+	switch http.DetectContentType(i.Content) {
+	case "image/jpeg":
+		filename = "image.jpg"
+	case "image/png":
+		filename = "image.png"
+	default:
+		return nil, errors.New("image must be jpg or png")
+	}
+
+	m, err := w.getMediaID(ctx, i.Content, IMAGE, filename)
+	if err != nil {
+		return nil, err
+	}
+	return w.sendMessage(ctx, &message{
+		Touser:                 i.Touser,
+		Toparty:                i.Toparty,
+		Totag:                  i.Totag,
+		AgentID:                i.AgentID,
+		Safe:                   i.Safe,
+		EnableDuplicateCheck:   i.EnableDuplicateCheck,
+		DuplicateCheckInterval: i.DuplicateCheckInterval,
+	}, string(IMAGE), map[string]string{
+		"media_id": m,
+	})
+}
+
+// AMR-NB各帧类型对应的帧数据长度（不含1字节帧头），每帧20ms
+var amrFrameSizes = [16]int{12, 13, 15, 17, 19, 20, 26, 31, 5, 6, 5, 5, 0, 0, 0, 0}
+
+func amrDuration(content []byte) (time.Duration, error) {
+	const header = "#!AMR\n"
+	if !bytes.HasPrefix(content, []byte(header)) {
+		return 0, errors.New("voice must be amr format")
+	}
+	frames := 0
+	for i := len(header); i < len(content); {
+		i += 1 + amrFrameSizes[(content[i]>>3)&0x0f]
+		frames++
+	}
+	return time.Duration(frames) * 20 * time.Millisecond, nil
+}
+
+type VoiceInfo struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+	// 语音大小不超过2MB，播放长度不超过60s，仅支持AMR格式
+	Content []byte
+
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) Voice(ctx context.Context, v *VoiceInfo) (*SendResult, error) {
+	d, err := amrDuration(v.Content)
+	if err != nil {
+		return nil, err
+	}
+	if d > 60*time.Second {
+		return nil, errors.New("voice duration must not exceed 60s")
+	}
+
+	m, err := w.getMediaID(ctx, v.Content, VOICE, "voice.amr")
+	if err != nil {
+		return nil, err
+	}
+	return w.sendMessage(ctx, &message{
+		Touser:                 v.Touser,
+		Toparty:                v.Toparty,
+		Totag:                  v.Totag,
+		AgentID:                v.AgentID,
+		EnableDuplicateCheck:   v.EnableDuplicateCheck,
+		DuplicateCheckInterval: v.DuplicateCheckInterval,
+	}, string(VOICE), map[string]string{
+		"media_id": m,
+	})
+}
+
+type VideoInfo struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+	Safe    bool
+	// 视频大小不超过10MB，支持MP4格式
+	Content []byte
+	// 默认为video.mp4
+	Filename    string
+	Title       string
+	Description string
+
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) Video(ctx context.Context, v *VideoInfo) (*SendResult, error) {
+	if v.Title == "" {
+		return nil, errors.New("video title is required")
+	}
+	if v.Description == "" {
+		return nil, errors.New("video description is required")
+	}
+	filename := v.Filename
+	if filename == "" {
+		filename = "video.mp4"
+	}
+
+	m, err := w.getMediaID(ctx, v.Content, VIDEO, filename)
+	if err != nil {
+		return nil, err
+	}
+	return w.sendMessage(ctx, &message{
+		Touser:                 v.Touser,
+		Toparty:                v.Toparty,
+		Totag:                  v.Totag,
+		AgentID:                v.AgentID,
+		Safe:                   v.Safe,
+		EnableDuplicateCheck:   v.EnableDuplicateCheck,
+		DuplicateCheckInterval: v.DuplicateCheckInterval,
+	}, string(VIDEO), map[string]string{
+		"media_id":    m,
+		"title":       v.Title,
+		"description": v.Description,
+	})
+}
+
+// 根据扩展名推断素材类型，无法识别时为FILE
+func filetypeOf(path string) Filetype {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png":
+		return IMAGE
+	case ".amr":
+		return VOICE
+	case ".mp4":
+		return VIDEO
+	default:
+		return FILE
+	}
+}
+
+// 发送本地文件，根据扩展名推断消息类型，文件以流的方式上传
+func (w *Wecom) FileFromPath(ctx context.Context, touser string, agentID int, path string) (*SendResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	filetype := filetypeOf(path)
+	filename := filepath.Base(path)
+	m, err := w.UploadMedia(ctx, f, fi.Size(), filetype, filename)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]string{
+		"media_id": m,
+	}
+	if filetype == VIDEO {
+		body["title"] = filename
+	}
+	return w.sendMessage(ctx, &message{
+		Touser:  touser,
+		AgentID: agentID,
+	}, string(filetype), body)
+}
@@ -0,0 +1,82 @@
+package wecom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type RequestMetric struct {
+	// 接口路径，如message/send
+	Endpoint string
+	// 仅发送消息的请求（包括重试）时有值
+	Msgtype string
+	// 响应中的errcode，请求失败或响应不含errcode时为0，此时以Err区分
+	Errcode  int
+	Err      error
+	Duration time.Duration
+}
+
+// 指标收集，prommetrics子包提供了Prometheus实现
+type Metrics interface {
+	ObserveRequest(m RequestMetric)
+	// access_token从gettoken接口刷新成功后调用
+	TokenRefreshed()
+}
+
+// Metrics可选实现的接口，每条消息发送结束后调用一次，重试及刷新access_token的请求不重复计入
+type MessageMetrics interface {
+	// 接口返回错误时errcode为对应的错误码，请求失败时为0，此时以err区分
+	ObserveMessage(msgtype string, errcode int, err error)
+}
+
+func (w *Wecom) observeMessage(msgtype string, err error) {
+	m, ok := w.metrics.(MessageMetrics)
+	if !ok {
+		return
+	}
+	errcode := 0
+	var e *Error
+	if errors.As(err, &e) {
+		errcode = e.Errcode
+	}
+	m.ObserveMessage(msgtype, errcode, err)
+}
+
+type msgtypeKey struct{}
+
+func withMsgtype(ctx context.Context, msgtype string) context.Context {
+	return context.WithValue(ctx, msgtypeKey{}, msgtype)
+}
+
+// 返回请求所属消息的msgtype，不是发送消息的请求时为空，可在RequestHook中通过r.Context()获取
+func MsgtypeFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(msgtypeKey{}).(string)
+	return s
+}
+
+// 接口路径，如message/send
+func endpoint(r *http.Request) string {
+	return strings.TrimPrefix(r.URL.Path, "/cgi-bin/")
+}
+
+func WithMetrics(m Metrics) Option {
+	return func(w *Wecom) {
+		w.metrics = m
+	}
+}
+
+// 解析响应中的errcode，不存在时ok为false
+func errcodeOf(body []byte) (errcode int, errmsg string, ok bool) {
+	e := struct {
+		ErrCode *int   `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}{}
+	if json.Unmarshal(body, &e) != nil || e.ErrCode == nil {
+		return 0, "", false
+	}
+	return *e.ErrCode, e.ErrMsg, true
+}
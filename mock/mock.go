@@ -0,0 +1,216 @@
+// Package mock 提供记录调用的wecom.Client实现，用于在单元测试中验证发送逻辑而不调用企业微信接口
+package mock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+// 一次方法调用
+type Call struct {
+	Method string
+	// 除ctx外的参数，顺序与方法签名相同
+	Args []any
+}
+
+// Client 记录所有调用，返回Err或成功的结果
+type Client struct {
+	lock  sync.Mutex
+	calls []Call
+	// 不为nil时所有方法返回该错误
+	Err error
+	// 不为nil时替代Err，按方法名决定返回的错误
+	ErrFunc func(method string, args []any) error
+}
+
+var _ wecom.Client = (*Client)(nil)
+
+func New() *Client {
+	return &Client{}
+}
+
+// 返回全部调用记录的副本
+func (c *Client) Calls() []Call {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// 返回method的调用记录
+func (c *Client) CallsOf(method string) []Call {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var r []Call
+	for _, call := range c.calls {
+		if call.Method == method {
+			r = append(r, call)
+		}
+	}
+	return r
+}
+
+// 清空调用记录
+func (c *Client) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.calls = nil
+}
+
+func (c *Client) record(method string, args []any) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+	if c.ErrFunc != nil {
+		return len(c.calls), c.ErrFunc(method, args)
+	}
+	return len(c.calls), c.Err
+}
+
+// 成功时返回的msgid依次为mock-msgid-1、mock-msgid-2……
+func (c *Client) send(method string, args ...any) (*wecom.SendResult, error) {
+	n, err := c.record(method, args)
+	if err != nil {
+		return nil, err
+	}
+	return &wecom.SendResult{MsgID: fmt.Sprintf("mock-msgid-%d", n)}, nil
+}
+
+func (c *Client) Text(ctx context.Context, t *wecom.TextInfo) (*wecom.SendResult, error) {
+	return c.send("Text", t)
+}
+
+func (c *Client) Markdown(ctx context.Context, m *wecom.MarkdownInfo) (*wecom.SendResult, error) {
+	return c.send("Markdown", m)
+}
+
+func (c *Client) TextCard(ctx context.Context, t *wecom.TextCardInfo) (*wecom.SendResult, error) {
+	return c.send("TextCard", t)
+}
+
+func (c *Client) News(ctx context.Context, n *wecom.NewsInfo) (*wecom.SendResult, error) {
+	return c.send("News", n)
+}
+
+func (c *Client) MPNews(ctx context.Context, n *wecom.MPNewsInfo) (*wecom.SendResult, error) {
+	return c.send("MPNews", n)
+}
+
+func (c *Client) MiniprogramNotice(ctx context.Context, m *wecom.MiniprogramNoticeInfo) (*wecom.SendResult, error) {
+	return c.send("MiniprogramNotice", m)
+}
+
+func (c *Client) Image(ctx context.Context, i *wecom.ImageInfo) (*wecom.SendResult, error) {
+	return c.send("Image", i)
+}
+
+func (c *Client) Voice(ctx context.Context, v *wecom.VoiceInfo) (*wecom.SendResult, error) {
+	return c.send("Voice", v)
+}
+
+func (c *Client) Video(ctx context.Context, v *wecom.VideoInfo) (*wecom.SendResult, error) {
+	return c.send("Video", v)
+}
+
+func (c *Client) File(ctx context.Context, f *wecom.FileInfo) (*wecom.SendResult, error) {
+	return c.send("File", f)
+}
+
+func (c *Client) FileFromPath(ctx context.Context, touser string, agentID int, path string) (*wecom.SendResult, error) {
+	return c.send("FileFromPath", touser, agentID, path)
+}
+
+func (c *Client) TemplateCard(ctx context.Context, t *wecom.TemplateCardInfo) (*wecom.SendResult, error) {
+	return c.send("TemplateCard", t)
+}
+
+func (c *Client) UpdateTemplateCard(ctx context.Context, u *wecom.UpdateTemplateCardInfo) error {
+	_, err := c.send("UpdateTemplateCard", u)
+	return err
+}
+
+func (c *Client) TaskCard(ctx context.Context, t *wecom.TaskCardInfo) (*wecom.SendResult, error) {
+	return c.send("TaskCard", t)
+}
+
+func (c *Client) UpdateTaskCard(ctx context.Context, agentID int, userids []string, taskID, clickedKey string) error {
+	_, err := c.send("UpdateTaskCard", agentID, userids, taskID, clickedKey)
+	return err
+}
+
+func (c *Client) SendRaw(ctx context.Context, msgtype string, body any) (*wecom.SendResult, error) {
+	return c.send("SendRaw", msgtype, body)
+}
+
+func (c *Client) SendTemplate(ctx context.Context, name string, data any, r wecom.Recipients) (*wecom.SendResult, error) {
+	return c.send("SendTemplate", name, data, r)
+}
+
+func (c *Client) Recall(ctx context.Context, msgid string) error {
+	_, err := c.send("Recall", msgid)
+	return err
+}
+
+func (c *Client) AppChatText(ctx context.Context, chatid, content string, safe bool) error {
+	_, err := c.send("AppChatText", chatid, content, safe)
+	return err
+}
+
+func (c *Client) AppChatMarkdown(ctx context.Context, chatid, content string) error {
+	_, err := c.send("AppChatMarkdown", chatid, content)
+	return err
+}
+
+func (c *Client) AppChatFile(ctx context.Context, chatid string, content []byte, filename string, safe bool) error {
+	_, err := c.send("AppChatFile", chatid, content, filename, safe)
+	return err
+}
+
+func (c *Client) BatchText(ctx context.Context, userids []string, t *wecom.TextInfo, parallel int) (*wecom.BatchResult, error) {
+	r, err := c.send("BatchText", userids, t, parallel)
+	if err != nil {
+		return nil, err
+	}
+	return &wecom.BatchResult{MsgIDs: []string{r.MsgID}}, nil
+}
+
+func (c *Client) LinkedCorpSend(ctx context.Context, m *wecom.LinkedCorpMessage) (*wecom.LinkedCorpResult, error) {
+	if _, err := c.record("LinkedCorpSend", []any{m}); err != nil {
+		return nil, err
+	}
+	return &wecom.LinkedCorpResult{}, nil
+}
+
+func (c *Client) SchoolSend(ctx context.Context, m *wecom.SchoolMessage) (*wecom.SchoolResult, error) {
+	if _, err := c.record("SchoolSend", []any{m}); err != nil {
+		return nil, err
+	}
+	return &wecom.SchoolResult{}, nil
+}
+
+// 不读取r，返回的media_id依次为mock-media-1、mock-media-2……
+func (c *Client) UploadMedia(ctx context.Context, r io.Reader, size int64, filetype wecom.Filetype, filename string) (string, error) {
+	n, err := c.record("UploadMedia", []any{r, size, filetype, filename})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("mock-media-%d", n), nil
+}
+
+func (c *Client) UploadImage(ctx context.Context, content []byte, filename string) (string, error) {
+	n, err := c.record("UploadImage", []any{content, filename})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://mock.invalid/image-%d", n), nil
+}
+
+// 不修改out
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	_, err := c.record("Do", []any{method, path, query, body, out})
+	return err
+}
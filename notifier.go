@@ -0,0 +1,28 @@
+package wecom
+
+import "context"
+
+// Notifier 发送markdown通知，*Bot及Notifier返回的应用消息发送者均实现了该接口，供日志、告警等适配器使用
+type Notifier interface {
+	Notify(ctx context.Context, markdown string) error
+}
+
+type appNotifier struct {
+	w       *Wecom
+	touser  string
+	agentID int
+}
+
+func (n *appNotifier) Notify(ctx context.Context, markdown string) error {
+	_, err := n.w.Markdown(ctx, &MarkdownInfo{Touser: n.touser, AgentID: n.agentID, Content: markdown})
+	return err
+}
+
+// 以应用消息发送给touser的Notifier，agentID为0时使用WithAgentID设置的默认值
+func (w *Wecom) Notifier(touser string, agentID int) Notifier {
+	return &appNotifier{w: w, touser: touser, agentID: agentID}
+}
+
+func (b *Bot) Notify(ctx context.Context, markdown string) error {
+	return b.Markdown(ctx, markdown)
+}
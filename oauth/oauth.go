@@ -0,0 +1,80 @@
+// Package oauth 封装企业微信网页授权登录，复用wecom客户端的access_token识别登录成员
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Doer 由wecom.New返回的客户端实现
+type Doer interface {
+	Do(ctx context.Context, method, path string, query url.Values, body, out any) error
+}
+
+type Scope string
+
+const (
+	// 静默授权，可获取成员的基础信息
+	ScopeBase Scope = "snsapi_base"
+	// 手动授权，可额外通过user_ticket获取敏感信息
+	ScopePrivateInfo Scope = "snsapi_privateinfo"
+)
+
+// 构造在企业微信内打开的网页授权链接，授权后跳转到redirectURI?code=CODE&state=STATE
+// ScopePrivateInfo时agentID必填
+func AuthorizeURL(corpid, redirectURI, state string, scope Scope, agentID int) string {
+	q := url.Values{}
+	q.Set("appid", corpid)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("response_type", "code")
+	q.Set("scope", string(scope))
+	if state != "" {
+		q.Set("state", state)
+	}
+	if agentID != 0 {
+		q.Set("agentid", strconv.Itoa(agentID))
+	}
+	return "https://open.weixin.qq.com/connect/oauth2/authorize?" + q.Encode() + "#wechat_redirect"
+}
+
+// 构造在浏览器中扫码登录的链接，登录后跳转到redirectURI?code=CODE&state=STATE
+func LoginURL(corpid, redirectURI, state string, agentID int) string {
+	q := url.Values{}
+	q.Set("login_type", "CorpApp")
+	q.Set("appid", corpid)
+	q.Set("agentid", strconv.Itoa(agentID))
+	q.Set("redirect_uri", redirectURI)
+	if state != "" {
+		q.Set("state", state)
+	}
+	return "https://login.work.weixin.qq.com/wwlogin/sso/login?" + q.Encode()
+}
+
+type Client struct {
+	d Doer
+}
+
+func New(d Doer) *Client {
+	return &Client{d: d}
+}
+
+// 企业成员时Userid不为空，非企业成员时Openid不为空
+type UserInfo struct {
+	Userid string `json:"userid"`
+	// 仅ScopePrivateInfo授权时返回，有效期为ExpiresIn秒
+	UserTicket     string `json:"user_ticket"`
+	ExpiresIn      int    `json:"expires_in"`
+	Openid         string `json:"openid"`
+	ExternalUserid string `json:"external_userid"`
+}
+
+// 根据跳转回来的code获取访问用户身份，code只能使用一次，5分钟未被使用自动过期
+func (c *Client) GetUserInfo(ctx context.Context, code string) (*UserInfo, error) {
+	u := &UserInfo{}
+	if err := c.d.Do(ctx, http.MethodGet, "auth/getuserinfo", url.Values{"code": {code}}, nil, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
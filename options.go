@@ -0,0 +1,88 @@
+package wecom
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Option func(*Wecom)
+
+// 设置默认的应用AgentID，消息中AgentID为0时使用
+func WithAgentID(agentID int) Option {
+	return func(w *Wecom) {
+		w.agentID = agentID
+	}
+}
+
+// 设置发送请求使用的http.Client，可自定义代理、连接池及TLS配置，默认为http.DefaultClient
+func WithHTTPClient(c *http.Client) Option {
+	return func(w *Wecom) {
+		if c == nil {
+			c = http.DefaultClient
+		}
+		w.httpClient = c
+	}
+}
+
+// 设置接口地址，默认为https://qyapi.weixin.qq.com/cgi-bin/，可用于代理或测试
+func WithBaseURL(baseURL string) Option {
+	return func(w *Wecom) {
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		w.baseURL = baseURL
+	}
+}
+
+// 设置单次请求的超时时间，默认10秒，为0时不限制；不会修改传入的http.Client，其Timeout仍然生效
+// 可通过WithTimeoutContext为单次调用单独设置
+func WithTimeout(d time.Duration) Option {
+	return func(w *Wecom) {
+		w.timeout = d
+	}
+}
+
+// 设置仅对当前客户端生效的代理，如http://127.0.0.1:8080，不会修改http.DefaultTransport
+// 仅在http.Client的Transport为nil或*http.Transport时生效，其他RoundTripper（如tracing包装）需自行配置代理
+func WithProxy(proxyURL string) Option {
+	return func(w *Wecom) {
+		w.proxy = proxyURL
+	}
+}
+
+// rt不是*http.Transport时ok为false，此时无法设置代理
+func proxyTransport(rt http.RoundTripper, proxyURL string) (_ http.RoundTripper, ok bool) {
+	var t *http.Transport
+	switch rt := rt.(type) {
+	case nil:
+	case *http.Transport:
+		t = rt
+	default:
+		return rt, false
+	}
+	if t == nil {
+		t = http.DefaultTransport.(*http.Transport)
+	}
+	t = t.Clone()
+	u, err := url.Parse(proxyURL)
+	t.Proxy = func(*http.Request) (*url.URL, error) {
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		return u, nil
+	}
+	return t, true
+}
+
+// 设置access_token失效时刷新并重试的最大次数，默认为1，为0时不重试
+func WithMaxTokenRetries(n int) Option {
+	return func(w *Wecom) {
+		if n < 0 {
+			n = 0
+		}
+		w.maxTokenRetries = n
+	}
+}
@@ -0,0 +1,79 @@
+// Package oteltrace 通过wecom的请求钩子为每次接口调用（gettoken、message/send、media/upload等）创建OpenTelemetry span
+//
+//	w := wecom.New(corpid, corpsecret, oteltrace.WithTracerProvider(otel.GetTracerProvider()))
+package oteltrace
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jzksnsjswkw/wecom-push"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/jzksnsjswkw/wecom-push/oteltrace"
+
+// 返回的Option同时添加请求钩子及响应钩子，span记录接口路径、msgtype、HTTP状态码及errcode
+func WithTracerProvider(tp trace.TracerProvider) wecom.Option {
+	t := &tracer{tracer: tp.Tracer(tracerName)}
+	return func(w *wecom.Wecom) {
+		wecom.WithRequestHook(t.start)(w)
+		wecom.WithResponseHook(t.end)(w)
+	}
+}
+
+type tracer struct {
+	tracer trace.Tracer
+	// 进行中的请求对应的span
+	spans sync.Map
+}
+
+// 接口路径，如message/send
+func endpoint(r *http.Request) string {
+	return strings.TrimPrefix(r.URL.Path, "/cgi-bin/")
+}
+
+func (t *tracer) start(r *http.Request) {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", r.Method),
+		attribute.String("wecom.endpoint", endpoint(r)),
+	}
+	if m := wecom.MsgtypeFromContext(r.Context()); m != "" {
+		attrs = append(attrs, attribute.String("wecom.msgtype", m))
+	}
+	_, span := t.tracer.Start(r.Context(), "wecom "+endpoint(r),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	t.spans.Store(r, span)
+}
+
+func (t *tracer) end(resp *wecom.Response, err error) {
+	v, ok := t.spans.LoadAndDelete(resp.Request)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	defer span.End()
+	if resp.StatusCode != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	}
+	e := struct {
+		ErrCode *int   `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}{}
+	if err == nil && json.Unmarshal(resp.Body, &e) == nil && e.ErrCode != nil {
+		span.SetAttributes(attribute.Int("wecom.errcode", *e.ErrCode))
+		if *e.ErrCode != 0 {
+			span.SetStatus(codes.Error, e.ErrMsg)
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,203 @@
+package wecom
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outbox.Send捕获消息时由sendMessage返回，不会返回给调用者
+var errCaptured = errors.New("wecom: message captured")
+
+type captureKey struct{}
+
+// 捕获sendMessage构造的消息而不发送
+type capture struct {
+	msgtype string
+	agentID int
+	payload any
+}
+
+func captureFrom(ctx context.Context) *capture {
+	c, _ := ctx.Value(captureKey{}).(*capture)
+	return c
+}
+
+type outboxEntry struct {
+	Msgtype     string          `json:"msgtype"`
+	AgentID     int             `json:"agentid"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// Outbox 将待发送的消息保存到本地目录，由Run在后台发送，进程重启后继续发送未完成的消息
+// 网络错误、5xx、系统繁忙及频率限制按RetryPolicy退避重试，其他错误及超过最大尝试次数的消息重命名为.failed文件
+type Outbox struct {
+	w        *Wecom
+	dir      string
+	retry    RetryPolicy
+	interval time.Duration
+	lock     sync.Mutex
+	wake     chan struct{}
+}
+
+// retry.MaxAttempts小于等于0时使用默认值：最多尝试10次，首次等待5秒，最长等待10分钟
+func (w *Wecom) NewOutbox(dir string, retry RetryPolicy) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if retry.MaxAttempts <= 0 {
+		retry = RetryPolicy{MaxAttempts: 10, InitialBackoff: 5 * time.Second, MaxBackoff: 10 * time.Minute, Jitter: 0.2}
+	}
+	return &Outbox{
+		w:        w,
+		dir:      dir,
+		retry:    retry,
+		interval: time.Second,
+		wake:     make(chan struct{}, 1),
+	}, nil
+}
+
+// 将send中构造的消息写入Outbox后立即返回，send中的发送方法使用传入的ctx，如
+//
+//	o.Send(ctx, func(ctx context.Context) (*wecom.SendResult, error) {
+//		return w.Text(ctx, t)
+//	})
+//
+// 图片、文件等素材在调用Send时即上传，media_id的有效期为3天
+func (o *Outbox) Send(ctx context.Context, send func(ctx context.Context) (*SendResult, error)) error {
+	c := &capture{}
+	if _, err := send(context.WithValue(ctx, captureKey{}, c)); !errors.Is(err, errCaptured) {
+		if err == nil {
+			err = errors.New("wecom: send did not build an app message")
+		}
+		return err
+	}
+	b, err := json.Marshal(c.payload)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	e := &outboxEntry{Msgtype: c.msgtype, AgentID: c.agentID, Payload: b, NextAttempt: now, CreatedAt: now}
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	name := strconv.FormatInt(now.UnixNano(), 10) + "-" + hex.EncodeToString(id) + ".json"
+	if err := o.write(name, e); err != nil {
+		return err
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// 先写临时文件再重命名，避免进程退出时留下写了一半的文件
+func (o *Outbox) write(name string, e *outboxEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(o.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(o.dir, name))
+}
+
+// 在后台发送Outbox中的消息，直到ctx取消
+func (o *Outbox) Run(ctx context.Context) error {
+	t := time.NewTicker(o.interval)
+	defer t.Stop()
+	for {
+		if err := o.Flush(ctx); err != nil {
+			o.w.logger.Printf("wecom: flush outbox: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// 按写入顺序发送所有已到重试时间的消息
+func (o *Outbox) Flush(ctx context.Context) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	des, err := os.ReadDir(o.dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, de := range des {
+		if !de.IsDir() && strings.HasSuffix(de.Name(), ".json") {
+			names = append(names, de.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := o.deliver(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *Outbox) deliver(ctx context.Context, name string) error {
+	path := filepath.Join(o.dir, name)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	e := &outboxEntry{}
+	if err := json.Unmarshal(b, e); err != nil {
+		o.w.logger.Printf("wecom: outbox %s is corrupted: %v", name, err)
+		return os.Rename(path, path+".failed")
+	}
+	if time.Now().Before(e.NextAttempt) {
+		return nil
+	}
+
+	_, err = o.w.postMessage(ctx, e.Msgtype, e.AgentID, e.Payload)
+	if err == nil {
+		return os.Remove(path)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	e.Attempts++
+	if !(isTransient(err) || errors.Is(err, ErrAPIFreqOutOfLimit)) || e.Attempts >= o.retry.MaxAttempts {
+		o.w.logger.Printf("wecom: outbox %s failed after %d attempts: %v", name, e.Attempts, err)
+		return os.Rename(path, path+".failed")
+	}
+	e.NextAttempt = time.Now().Add(o.retry.backoff(e.Attempts - 1))
+	o.w.logger.Printf("wecom: outbox %s failed, retrying at %s: %v", name, e.NextAttempt.Format(time.RFC3339), err)
+	return o.write(name, e)
+}
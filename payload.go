@@ -0,0 +1,56 @@
+package wecom
+
+// 以下函数构造与发送时相同的message/send请求体，可用于发送前检查、序列化或投递到其他队列，之后通过SendRaw发送：
+//
+//	d := wecom.BuildTextPayload(&wecom.TextInfo{Touser: "Pony", Content: "test"})
+//	_, err := w.SendRaw(ctx, "text", d)
+//
+// AgentID为0时请求体中不包含agentid，由SendRaw使用WithAgentID设置的默认值
+// 图片、语音、文件等需要先上传素材的消息类型不提供
+
+func BuildTextPayload(t *TextInfo) map[string]any {
+	m, body := textMessage(t)
+	return buildPayload(m, "text", body)
+}
+
+func BuildMarkdownPayload(m *MarkdownInfo) map[string]any {
+	msg, body := markdownMessage(m)
+	return buildPayload(msg, "markdown", body)
+}
+
+func BuildTextCardPayload(t *TextCardInfo) map[string]any {
+	m, body := textCardMessage(t)
+	return buildPayload(m, "textcard", body)
+}
+
+func BuildNewsPayload(n *NewsInfo) (map[string]any, error) {
+	m, body, err := newsMessage(n)
+	if err != nil {
+		return nil, err
+	}
+	return buildPayload(m, "news", body), nil
+}
+
+func BuildMiniprogramNoticePayload(m *MiniprogramNoticeInfo) (map[string]any, error) {
+	msg, body, err := miniprogramNoticeMessage(m)
+	if err != nil {
+		return nil, err
+	}
+	return buildPayload(msg, "miniprogram_notice", body), nil
+}
+
+func BuildTemplateCardPayload(t *TemplateCardInfo) (map[string]any, error) {
+	m, body, err := templateCardMessage(t)
+	if err != nil {
+		return nil, err
+	}
+	return buildPayload(m, "template_card", body), nil
+}
+
+func BuildTaskCardPayload(t *TaskCardInfo) (map[string]any, error) {
+	m, body, err := taskCardMessage(t)
+	if err != nil {
+		return nil, err
+	}
+	return buildPayload(m, "interactive_taskcard", body), nil
+}
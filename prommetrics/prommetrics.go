@@ -0,0 +1,99 @@
+// Package prommetrics 提供基于Prometheus的wecom.Metrics实现
+package prommetrics
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/jzksnsjswkw/wecom-push"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector 同时实现了wecom.Metrics和prometheus.Collector
+//
+//	c := prommetrics.New("myapp")
+//	prometheus.MustRegister(c)
+//	w := wecom.New(corpid, corpsecret, wecom.WithMetrics(c))
+type Collector struct {
+	sends          *prometheus.CounterVec
+	requests       *prometheus.CounterVec
+	tokenRefreshes prometheus.Counter
+	duration       *prometheus.HistogramVec
+}
+
+var _ wecom.MessageMetrics = (*Collector)(nil)
+
+func New(namespace string) *Collector {
+	return &Collector{
+		sends: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "wecom",
+			Name:      "messages_sent_total",
+			Help:      "Number of application messages sent, by msgtype and errcode.",
+		}, []string{"msgtype", "errcode"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "wecom",
+			Name:      "requests_total",
+			Help:      "Number of WeCom API requests, by endpoint and errcode.",
+		}, []string{"endpoint", "errcode"}),
+		tokenRefreshes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "wecom",
+			Name:      "token_refreshes_total",
+			Help:      "Number of access_token refreshes from gettoken.",
+		}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "wecom",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of WeCom API requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+	}
+}
+
+// 网络错误为"error"，HTTP状态码错误为"http_<code>"
+func errcodeLabel(m wecom.RequestMetric) string {
+	if m.Err != nil {
+		var se *wecom.StatusError
+		if errors.As(m.Err, &se) {
+			return "http_" + strconv.Itoa(se.StatusCode)
+		}
+		return "error"
+	}
+	return strconv.Itoa(m.Errcode)
+}
+
+func (c *Collector) ObserveRequest(m wecom.RequestMetric) {
+	code := errcodeLabel(m)
+	c.requests.WithLabelValues(m.Endpoint, code).Inc()
+	c.duration.WithLabelValues(m.Endpoint).Observe(m.Duration.Seconds())
+}
+
+// 每条消息只计一次，errcode为最终结果
+func (c *Collector) ObserveMessage(msgtype string, errcode int, err error) {
+	code := strconv.Itoa(errcode)
+	if errcode == 0 {
+		code = errcodeLabel(wecom.RequestMetric{Err: err})
+	}
+	c.sends.WithLabelValues(msgtype, code).Inc()
+}
+
+func (c *Collector) TokenRefreshed() {
+	c.tokenRefreshes.Inc()
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.sends.Describe(ch)
+	c.requests.Describe(ch)
+	c.tokenRefreshes.Describe(ch)
+	c.duration.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.sends.Collect(ch)
+	c.requests.Collect(ch)
+	c.tokenRefreshes.Collect(ch)
+	c.duration.Collect(ch)
+}
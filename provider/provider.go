@@ -0,0 +1,206 @@
+// Package provider 提供服务商模式下的凭证，包括第三方应用的suite_access_token及授权企业的access_token
+// CorpTokenSource实现了wecom.TokenSource，可通过wecom.WithTokenSource复用全部消息接口
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+const defaultBaseURL = "https://qyapi.weixin.qq.com/cgi-bin/"
+
+// 凭证提前刷新的时间，避免请求途中过期
+const refreshMargin = 5 * time.Minute
+
+type Option func(*Suite)
+
+// 默认为http.DefaultClient
+func WithHTTPClient(c *http.Client) Option {
+	return func(s *Suite) {
+		if c == nil {
+			c = http.DefaultClient
+		}
+		s.httpClient = c
+	}
+}
+
+// 默认为https://qyapi.weixin.qq.com/cgi-bin/
+func WithBaseURL(u string) Option {
+	return func(s *Suite) {
+		if !strings.HasSuffix(u, "/") {
+			u += "/"
+		}
+		s.baseURL = u
+	}
+}
+
+// 带缓存的凭证，过期或失效后重新获取
+type cachedToken struct {
+	lock      sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// fetch返回凭证及其有效期（秒），并发调用时只有一个调用者获取
+func (c *cachedToken) get(ctx context.Context, fetch func(ctx context.Context) (string, int, error)) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		return c.token, nil
+	}
+	token, expiresIn, err := fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expiresAt = time.Now().Add(time.Duration(expiresIn)*time.Second - refreshMargin)
+	return token, nil
+}
+
+// 仅当缓存的仍是token时清除，避免清除其他调用者刚获取的凭证
+func (c *cachedToken) invalidate(token string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// Suite 第三方应用，suite_ticket由企业微信每十分钟推送到指令回调URL，需调用SetTicket更新
+type Suite struct {
+	suiteID     string
+	suiteSecret string
+	httpClient  *http.Client
+	baseURL     string
+
+	ticketLock sync.RWMutex
+	ticket     string
+	token      cachedToken
+}
+
+func NewSuite(suiteID, suiteSecret string, opts ...Option) *Suite {
+	s := &Suite{
+		suiteID:     suiteID,
+		suiteSecret: suiteSecret,
+		httpClient:  http.DefaultClient,
+		baseURL:     defaultBaseURL,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// 更新suite_ticket，收到suite_ticket推送时调用
+func (s *Suite) SetTicket(ticket string) {
+	s.ticketLock.Lock()
+	defer s.ticketLock.Unlock()
+	s.ticket = ticket
+}
+
+// 返回suite_access_token，用于调用服务商的service/*接口
+func (s *Suite) Token(ctx context.Context) (string, error) {
+	return s.token.get(ctx, func(ctx context.Context) (string, int, error) {
+		s.ticketLock.RLock()
+		ticket := s.ticket
+		s.ticketLock.RUnlock()
+		r := struct {
+			SuiteAccessToken string `json:"suite_access_token"`
+			ExpiresIn        int    `json:"expires_in"`
+		}{}
+		err := s.post(ctx, "service/get_suite_token", nil, map[string]string{
+			"suite_id":     s.suiteID,
+			"suite_secret": s.suiteSecret,
+			"suite_ticket": ticket,
+		}, &r)
+		return r.SuiteAccessToken, r.ExpiresIn, err
+	})
+}
+
+func (s *Suite) Invalidate(ctx context.Context, token string) {
+	s.token.invalidate(token)
+}
+
+func (s *Suite) post(ctx context.Context, path string, query url.Values, d, out any) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	u := s.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	r.Header.Add("content-type", "application/json")
+	r.Header.Add("accept", "application/json")
+	resp, err := s.httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return &wecom.StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	body := struct {
+		Errcode int    `json:"errcode"`
+		Errmsg  string `json:"errmsg"`
+	}{}
+	b, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return err
+	}
+	if body.Errcode != 0 {
+		return &wecom.Error{Errcode: body.Errcode, Errmsg: body.Errmsg}
+	}
+	return json.Unmarshal(b, out)
+}
+
+// CorpTokenSource 授权企业的access_token，实现了wecom.TokenSource及wecom.TokenInvalidator
+type CorpTokenSource struct {
+	suite         *Suite
+	authCorpID    string
+	permanentCode string
+	token         cachedToken
+}
+
+// authCorpID及permanentCode为企业授权时获取的授权方corpid及永久授权码
+func (s *Suite) CorpTokenSource(authCorpID, permanentCode string) *CorpTokenSource {
+	return &CorpTokenSource{suite: s, authCorpID: authCorpID, permanentCode: permanentCode}
+}
+
+func (c *CorpTokenSource) Token(ctx context.Context) (string, error) {
+	return c.token.get(ctx, func(ctx context.Context) (string, int, error) {
+		suiteToken, err := c.suite.Token(ctx)
+		if err != nil {
+			return "", 0, err
+		}
+		r := struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}{}
+		err = c.suite.post(ctx, "service/get_corp_token", url.Values{"suite_access_token": {suiteToken}}, map[string]string{
+			"auth_corpid":    c.authCorpID,
+			"permanent_code": c.permanentCode,
+		}, &r)
+		return r.AccessToken, r.ExpiresIn, err
+	})
+}
+
+func (c *CorpTokenSource) Invalidate(ctx context.Context, token string) {
+	c.token.invalidate(token)
+}
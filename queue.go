@@ -0,0 +1,104 @@
+package wecom
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrQueueFull   = errors.New("wecom: queue is full")
+	ErrQueueClosed = errors.New("wecom: queue is closed")
+)
+
+// 异步发送的结果
+type QueueResult struct {
+	// Enqueue返回的id
+	ID     uint64
+	Result *SendResult
+	Err    error
+}
+
+type queueJob struct {
+	id   uint64
+	send func(ctx context.Context) (*SendResult, error)
+	done func(*SendResult, error)
+}
+
+// Queue 在后台异步发送消息，发送完成后通过回调或结果channel通知调用者
+type Queue struct {
+	ctx     context.Context
+	jobs    chan *queueJob
+	results chan<- QueueResult
+	nextID  atomic.Uint64
+	lock    sync.RWMutex
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// size为队列长度，workers为并发发送的goroutine数
+// results不为nil时每条消息发送完成后将结果写入results，调用者需及时读取，否则会阻塞发送
+// ctx取消后尚未发送的消息将以ctx.Err()结束
+func NewQueue(ctx context.Context, size, workers int, results chan<- QueueResult) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	q := &Queue{
+		ctx:     ctx,
+		jobs:    make(chan *queueJob, size),
+		results: results,
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for j := range q.jobs {
+		var (
+			r   *SendResult
+			err = q.ctx.Err()
+		)
+		if err == nil {
+			r, err = j.send(q.ctx)
+		}
+		if j.done != nil {
+			j.done(r, err)
+		}
+		if q.results != nil {
+			q.results <- QueueResult{ID: j.id, Result: r, Err: err}
+		}
+	}
+}
+
+// 将send加入队列，返回用于匹配QueueResult的id，队列已满时返回ErrQueueFull
+// done不为nil时在发送完成后于worker goroutine中调用
+func (q *Queue) Enqueue(send func(ctx context.Context) (*SendResult, error), done func(*SendResult, error)) (uint64, error) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if q.closed {
+		return 0, ErrQueueClosed
+	}
+	j := &queueJob{id: q.nextID.Add(1), send: send, done: done}
+	select {
+	case q.jobs <- j:
+		return j.id, nil
+	default:
+		return 0, ErrQueueFull
+	}
+}
+
+// 停止接收新消息并等待队列中的消息发送完成，不会关闭results
+func (q *Queue) Close() {
+	q.lock.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.lock.Unlock()
+	q.wg.Wait()
+}
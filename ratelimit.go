@@ -0,0 +1,19 @@
+package wecom
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// 限制每分钟最多发送n条应用消息，超出时阻塞等待直到ctx取消，n小于等于0时不限制
+// 消息按固定间隔均匀发出，不允许突发，确保任意一分钟内不超过n条
+func WithRateLimit(n int) Option {
+	return func(w *Wecom) {
+		if n <= 0 {
+			w.limiter = nil
+			return
+		}
+		w.limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(n)), 1)
+	}
+}
@@ -0,0 +1,52 @@
+// Package redisstore 提供基于Redis的wecom.TokenStore实现，供多个实例共享access_token
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store 实现了wecom.TokenStore
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+type Option func(*Store)
+
+// 为key添加前缀，默认无前缀
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// client可以是*redis.Client、*redis.ClusterClient或*redis.Ring
+func New(client redis.UniversalClient, opts ...Option) *Store {
+	s := &Store{client: client}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Store) Get(ctx context.Context, key string) (string, time.Duration, error) {
+	key = s.prefix + key
+	pipe := s.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", 0, nil
+		}
+		return "", 0, err
+	}
+	return get.Val(), ttl.Val(), nil
+}
+
+func (s *Store) Set(ctx context.Context, key, token string, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, token, ttl).Err()
+}
@@ -0,0 +1,91 @@
+package wecom
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// 网络错误、5xx响应及系统繁忙(-1)等临时性错误的重试策略，与access_token失效的重试相互独立
+type RetryPolicy struct {
+	// 最大尝试次数（包含首次请求），小于等于1时不重试
+	MaxAttempts int
+	// 首次重试前的等待时间，之后每次翻倍
+	InitialBackoff time.Duration
+	// 单次等待时间的上限，为0时不限制
+	MaxBackoff time.Duration
+	// 随机抖动比例，取值0~1，如0.2表示在等待时间上下浮动20%
+	Jitter float64
+}
+
+// 设置临时性错误的重试策略，默认不重试
+func WithRetry(p RetryPolicy) Option {
+	return func(w *Wecom) {
+		w.retry = p
+	}
+}
+
+// 触发接口频率限制(45009)时等待delay后重试，最多重试maxRetries次，为0时直接返回错误
+// 默认等待1秒，最多重试3次
+func WithRateLimitRetry(delay time.Duration, maxRetries int) Option {
+	return func(w *Wecom) {
+		if maxRetries < 0 {
+			maxRetries = 0
+		}
+		w.rateLimitDelay = delay
+		w.rateLimitRetries = maxRetries
+	}
+}
+
+// 是否为可重试的临时性错误
+func isTransient(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode >= 500
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Errcode == -1
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// retries为已重试的次数
+func (p RetryPolicy) shouldRetry(ctx context.Context, err error, retries int) bool {
+	if retries+1 >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	return isTransient(err)
+}
+
+func (p RetryPolicy) backoff(retries int) time.Duration {
+	d := p.InitialBackoff
+	for i := 0; i < retries && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	return d
+}
+
+func (p RetryPolicy) wait(ctx context.Context, retries int) error {
+	return sleep(ctx, p.backoff(retries))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package wecom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// 家校消息，发送给学生及家长
+type SchoolMessage struct {
+	// 0发送给学生和家长，1仅发送给学生，2仅发送给家长，默认0
+	RecvScope       int
+	ToParentUserid  []string
+	ToStudentUserid []string
+	ToParty         []string
+	// 发送给应用可见范围内的所有人，需先调用AllowToAll(true)
+	ToAll   bool
+	AgentID int
+	// 支持text、image、voice、video、file、news、mpnews、miniprogram
+	Msgtype string
+	// 对应消息类型的内容，设置Media时可为空
+	Content any
+	// 附件内容，Msgtype为image、voice、video、file时自动上传并以media_id发送
+	Media    []byte
+	Filename string
+
+	EnableIDTrans          bool
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+type SchoolResult struct {
+	InvalidParentUserid  []string `json:"invalid_parent_userid"`
+	InvalidStudentUserid []string `json:"invalid_student_userid"`
+	InvalidParty         []string `json:"invalid_party"`
+}
+
+func (w *Wecom) SchoolSend(ctx context.Context, m *SchoolMessage) (*SchoolResult, error) {
+	if m.Msgtype == "" {
+		return nil, errors.New("school message requires msgtype")
+	}
+	if m.ToAll && !w.allowToAll {
+		return nil, ErrToAllNotAllowed
+	}
+	// 上传素材及发送均使用该应用的access_token
+	ctx = WithAgentContext(ctx, w.agent(m.AgentID))
+
+	content := m.Content
+	if m.Media != nil {
+		switch Filetype(m.Msgtype) {
+		case IMAGE, VOICE, VIDEO, FILE:
+		default:
+			return nil, errors.New("media is only supported for image, voice, video and file")
+		}
+		id, err := w.getMediaID(ctx, m.Media, Filetype(m.Msgtype), m.Filename)
+		if err != nil {
+			return nil, err
+		}
+		content = map[string]string{"media_id": id}
+	}
+
+	d := map[string]any{
+		"recv_scope":        m.RecvScope,
+		"to_parent_userid":  m.ToParentUserid,
+		"to_student_userid": m.ToStudentUserid,
+		"to_party":          m.ToParty,
+		"agentid":           w.agent(m.AgentID),
+		"msgtype":           m.Msgtype,
+		m.Msgtype:           content,
+	}
+	if m.ToAll {
+		d["toall"] = 1
+	}
+	if m.EnableIDTrans {
+		d["enable_id_trans"] = 1
+	}
+	if m.EnableDuplicateCheck {
+		d["enable_duplicate_check"] = 1
+		if m.DuplicateCheckInterval > 0 {
+			d["duplicate_check_interval"] = m.DuplicateCheckInterval
+		}
+	}
+	b, err := w.post(withMsgtype(ctx, m.Msgtype), "externalcontact/message/send", d)
+	w.observeMessage(m.Msgtype, err)
+	if err != nil {
+		return nil, err
+	}
+	r := &SchoolResult{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
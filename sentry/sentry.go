@@ -0,0 +1,197 @@
+// Package sentry 提供接收Sentry告警webhook的http.Handler，将问题告警以文本卡片消息推送
+package sentry
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jzksnsjswkw/wecom-push"
+)
+
+// Sender 由wecom.New返回的客户端实现
+type Sender interface {
+	TextCard(ctx context.Context, t *wecom.TextCardInfo) (*wecom.SendResult, error)
+}
+
+// Router 根据项目slug返回接收者，ok为false时不推送
+type Router func(project string) (r wecom.Recipients, ok bool)
+
+// 从两种webhook转换而来的告警
+type Issue struct {
+	Title   string
+	Culprit string
+	Level   string
+	Project string
+	// 告警规则名称
+	Rule string
+	URL  string
+}
+
+type Handler struct {
+	s      Sender
+	router Router
+	secret string
+}
+
+// 同时支持集成平台（Internal Integration）的event_alert及旧版WebHooks插件的请求
+// secret为集成的Client Secret，不为空时校验Sentry-Hook-Signature，旧版插件没有签名，需留空
+func NewHandler(s Sender, router Router, secret string) *Handler {
+	return &Handler{s: s, router: router, secret: secret}
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, 5<<20))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.secret != "" {
+		mac := hmac.New(sha256.New, []byte(h.secret))
+		mac.Write(body)
+		got, err := hex.DecodeString(r.Header.Get("Sentry-Hook-Signature"))
+		if err != nil || !hmac.Equal(got, mac.Sum(nil)) {
+			http.Error(rw, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var issue *Issue
+	switch resource := r.Header.Get("Sentry-Hook-Resource"); resource {
+	case "event_alert":
+		issue, err = parseEventAlert(body)
+	case "":
+		issue, err = parseLegacy(body)
+	default:
+		// installation、issue等其他资源不推送
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.Notify(r.Context(), issue); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadGateway)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+func parseEventAlert(body []byte) (*Issue, error) {
+	p := struct {
+		Data struct {
+			Event struct {
+				Title    string `json:"title"`
+				Culprit  string `json:"culprit"`
+				Level    string `json:"level"`
+				WebURL   string `json:"web_url"`
+				IssueURL string `json:"issue_url"`
+				Project  int    `json:"project"`
+			} `json:"event"`
+			TriggeredRule string `json:"triggered_rule"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	e := p.Data.Event
+	return &Issue{
+		Title:   e.Title,
+		Culprit: e.Culprit,
+		Level:   e.Level,
+		// event_alert中只有项目id
+		Project: fmt.Sprint(e.Project),
+		Rule:    p.Data.TriggeredRule,
+		URL:     e.WebURL,
+	}, nil
+}
+
+func parseLegacy(body []byte) (*Issue, error) {
+	p := struct {
+		Message         string   `json:"message"`
+		Culprit         string   `json:"culprit"`
+		Level           string   `json:"level"`
+		URL             string   `json:"url"`
+		ProjectSlug     string   `json:"project_slug"`
+		TriggeringRules []string `json:"triggering_rules"`
+		Event           struct {
+			Title string `json:"title"`
+		} `json:"event"`
+	}{}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	title := p.Event.Title
+	if title == "" {
+		title = p.Message
+	}
+	return &Issue{
+		Title:   title,
+		Culprit: p.Culprit,
+		Level:   p.Level,
+		Project: p.ProjectSlug,
+		Rule:    strings.Join(p.TriggeringRules, ", "),
+		URL:     p.URL,
+	}, nil
+}
+
+// 以文本卡片推送issue
+func (h *Handler) Notify(ctx context.Context, issue *Issue) error {
+	rcpt, ok := h.router(issue.Project)
+	if !ok {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "<div class=\"gray\">项目：%s</div>", html.EscapeString(issue.Project))
+	if issue.Rule != "" {
+		fmt.Fprintf(&b, "<div class=\"gray\">规则：%s</div>", html.EscapeString(issue.Rule))
+	}
+	const culpritTag = "<div class=\"highlight\"></div>"
+	if n := maxDescription - b.Len() - len(culpritTag); issue.Culprit != "" && n > 0 {
+		fmt.Fprintf(&b, "<div class=\"highlight\">%s</div>", truncateEscaped(html.EscapeString(issue.Culprit), n))
+	}
+	title := issue.Title
+	if issue.Level != "" {
+		title = "[" + strings.ToUpper(issue.Level) + "] " + title
+	}
+	_, err := h.s.TextCard(ctx, &wecom.TextCardInfo{
+		Touser:      rcpt.Touser,
+		Toparty:     rcpt.Toparty,
+		Totag:       rcpt.Totag,
+		AgentID:     rcpt.AgentID,
+		Title:       wecom.TruncateToBytes(title, maxTitle),
+		Description: wecom.TruncateToBytes(b.String(), maxDescription),
+		URL:         issue.URL,
+		Btntxt:      "查看问题",
+	})
+	return err
+}
+
+// 文本卡片的标题及描述长度上限，超出时会被拒绝发送
+const (
+	maxTitle       = 128
+	maxDescription = 512
+)
+
+// 截断已转义的文本，不留下不完整的字符实体
+func truncateEscaped(s string, n int) string {
+	t := wecom.TruncateToBytes(s, n)
+	if len(t) < len(s) {
+		if i := strings.LastIndexByte(t, '&'); i >= 0 && !strings.Contains(t[i:], ";") {
+			t = t[:i]
+		}
+	}
+	return t
+}
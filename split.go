@@ -0,0 +1,74 @@
+package wecom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// 文本消息内容超过2048字节时不再返回ErrContentTooLong，而是尽量在换行处拆分为多条依次发送，
+// 每条开头加上“(1/3)”形式的序号，适用于推送日志片段等场景
+// 中途发送失败时返回的错误包含失败的序号，之前的消息已发出
+func WithSplitLongText() Option {
+	return func(w *Wecom) {
+		w.splitLongText = true
+	}
+}
+
+// 不需要拆分时返回nil
+// Outbox捕获消息时不拆分，以免只保存第一条
+func (w *Wecom) splitLongContent(ctx context.Context, msgtype string, body any) []string {
+	if !w.splitLongText || msgtype != "text" || captureFrom(ctx) != nil {
+		return nil
+	}
+	d, ok := body.(map[string]string)
+	if !ok || len(d["content"]) <= maxTextBytes {
+		return nil
+	}
+	return splitText(d["content"], maxTextBytes)
+}
+
+// 拆分后每条加上序号仍不超过max字节
+func splitText(s string, max int) []string {
+	// 按序号的位数预留空间，拆分出的条数超出时增加一位重新拆分
+	for limit := 9; ; limit = limit*10 + 9 {
+		prefix := len(partPrefix(limit, limit))
+		parts := splitChunks(s, max-prefix)
+		if len(parts) <= limit {
+			for i := range parts {
+				parts[i] = partPrefix(i+1, len(parts)) + parts[i]
+			}
+			return parts
+		}
+	}
+}
+
+func partPrefix(i, n int) string {
+	return fmt.Sprintf("(%d/%d)\n", i, n)
+}
+
+func splitChunks(s string, max int) []string {
+	var parts []string
+	for len(s) > 0 {
+		n := textChunk(s, max)
+		parts = append(parts, s[:n])
+		// 在换行处拆分时去掉该换行符
+		s = strings.TrimPrefix(s[n:], "\n")
+	}
+	return parts
+}
+
+func (w *Wecom) sendParts(ctx context.Context, m *message, parts []string) (*SendResult, error) {
+	var r *SendResult
+	for i, p := range parts {
+		pr, err := w.sendMessage(ctx, m, "text", map[string]string{"content": p})
+		if err != nil {
+			return nil, fmt.Errorf("wecom: send part %d/%d: %w", i+1, len(parts), err)
+		}
+		if r == nil {
+			r = pr
+		}
+		r.PartMsgIDs = append(r.PartMsgIDs, pr.MsgID)
+	}
+	return r, nil
+}
@@ -0,0 +1,48 @@
+package wecom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSplitText(t *testing.T) {
+	lines := make([]string, 300)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %03d", i)
+	}
+	tests := []struct {
+		name  string
+		s     string
+		max   int
+		parts int
+	}{
+		{"lines", strings.Join(lines, "\n"), 100, 30},
+		{"no newline", strings.Repeat("a", 250), 100, 3},
+		{"multibyte", strings.Repeat("中", 100), 100, 4},
+		{"ten or more parts", strings.Repeat("a", 1000), 100, 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := splitText(tt.s, tt.max)
+			if len(parts) != tt.parts {
+				t.Fatalf("got %d parts, want %d", len(parts), tt.parts)
+			}
+			var b strings.Builder
+			for i, p := range parts {
+				if len(p) > tt.max {
+					t.Errorf("part %d is %d bytes, limit %d", i+1, len(p), tt.max)
+				}
+				prefix := partPrefix(i+1, len(parts))
+				if !strings.HasPrefix(p, prefix) {
+					t.Fatalf("part %d = %q, want prefix %q", i+1, p, prefix)
+				}
+				b.WriteString(strings.TrimPrefix(p, prefix))
+			}
+			// 在换行处拆分时去掉了该换行符
+			if want := strings.ReplaceAll(tt.s, "\n", ""); strings.ReplaceAll(b.String(), "\n", "") != want {
+				t.Errorf("joined parts do not match the original content")
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package wecom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSuppressed = errors.New("wecom: duplicate message suppressed")
+
+type suppressEntry struct {
+	sentAt time.Time
+	// 自sentAt以来被抑制的次数
+	count int
+}
+
+type suppressor struct {
+	lock    sync.Mutex
+	window  time.Duration
+	entries map[string]*suppressEntry
+}
+
+// 在客户端抑制window内接收者、类型及内容均相同的应用消息，被抑制的调用返回ErrSuppressed
+// window过后再次发送相同的text或markdown消息时，在内容末尾注明此前被抑制的次数
+// 与enable_duplicate_check不同，被抑制的消息不会调用接口，也不占用发送频率
+func WithSuppressor(window time.Duration) Option {
+	return func(w *Wecom) {
+		w.suppressor = &suppressor{window: window, entries: map[string]*suppressEntry{}}
+	}
+}
+
+func suppressKey(m *message, msgtype string, body any) (string, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, s := range []string{m.Touser, m.Toparty, m.Totag, msgtype} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// 返回是否发送、上次发送后被抑制的次数，以及发送失败时用于撤销本次记录的函数
+func (s *suppressor) check(key string) (bool, int, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	prev, ok := s.entries[key]
+	if ok && now.Sub(prev.sentAt) < s.window {
+		prev.count++
+		return false, 0, nil
+	}
+	n := 0
+	if ok {
+		n = prev.count
+	}
+	for k, e := range s.entries {
+		// 有抑制次数的记录过期后再保留一个window，以便下次发送时注明，之后同样删除
+		if age := now.Sub(e.sentAt); age >= 2*s.window || (age >= s.window && e.count == 0) {
+			delete(s.entries, k)
+		}
+	}
+	e := &suppressEntry{sentAt: now}
+	s.entries[key] = e
+	return true, n, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		// 期间未被再次发送覆盖时恢复为之前的记录，抑制次数并入其中
+		if s.entries[key] != e {
+			return
+		}
+		if prev == nil {
+			if e.count == 0 {
+				delete(s.entries, key)
+				return
+			}
+			prev = &suppressEntry{sentAt: now.Add(-s.window)}
+		}
+		prev.count += e.count
+		s.entries[key] = prev
+	}
+}
+
+// 应用WithSuppressor，返回实际发送的消息内容及发送失败时需调用的撤销函数
+func (w *Wecom) suppress(m *message, msgtype string, body any) (any, func(), error) {
+	if w.suppressor == nil {
+		return body, func() {}, nil
+	}
+	key, err := suppressKey(m, msgtype, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	ok, n, undo := w.suppressor.check(key)
+	if !ok {
+		return nil, nil, ErrSuppressed
+	}
+	c, isMap := body.(map[string]string)
+	if n == 0 || !isMap || (msgtype != "text" && msgtype != "markdown") {
+		return body, undo, nil
+	}
+	// 5m0s显示为5m，1h0m0s显示为1h
+	window := w.suppressor.window.String()
+	if strings.HasSuffix(window, "m0s") {
+		window = strings.TrimSuffix(window, "0s")
+	}
+	if strings.HasSuffix(window, "h0m") {
+		window = strings.TrimSuffix(window, "0m")
+	}
+	note := fmt.Sprintf("\n（上次发送后%s内重复%d次）", window, n)
+	max := maxTextBytes
+	if msgtype == "markdown" {
+		max = maxMarkdownBytes
+	}
+	// 内容已经过校验，为说明预留长度，避免加上后超出限制
+	content := c["content"]
+	if len(content)+len(note) > max {
+		content = TruncateToBytes(content, max-len(note)-len(truncatedSuffix)) + truncatedSuffix
+	}
+	return map[string]string{
+		"content": content + note,
+	}, undo, nil
+}
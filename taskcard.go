@@ -0,0 +1,76 @@
+package wecom
+
+import (
+	"context"
+	"errors"
+)
+
+type TaskCardBtn struct {
+	// 按钮key值，用户点击后会产生回调事件将本参数作为EventKey返回
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	// 点击按钮后显示的名称，默认为“已处理”
+	ReplaceName string `json:"replace_name,omitempty"`
+	// 按钮字体颜色，可选red或blue，默认为blue
+	Color  string `json:"color,omitempty"`
+	IsBold bool   `json:"is_bold,omitempty"`
+}
+
+type TaskCardInfo struct {
+	Touser      string
+	Toparty     string
+	Totag       string
+	AgentID     int
+	Title       string
+	Description string
+	URL         string
+	// 同一个应用内不可重复
+	TaskID string
+	// 1~2个
+	Btn []TaskCardBtn
+
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) TaskCard(ctx context.Context, t *TaskCardInfo) (*SendResult, error) {
+	m, body, err := taskCardMessage(t)
+	if err != nil {
+		return nil, err
+	}
+	return w.sendMessage(ctx, m, "interactive_taskcard", body)
+}
+
+func taskCardMessage(t *TaskCardInfo) (*message, any, error) {
+	if t.TaskID == "" {
+		return nil, nil, errors.New("taskcard requires task_id")
+	}
+	if len(t.Btn) == 0 || len(t.Btn) > 2 {
+		return nil, nil, errors.New("taskcard btn count must be between 1 and 2")
+	}
+	return &message{
+		Touser:                 t.Touser,
+		Toparty:                t.Toparty,
+		Totag:                  t.Totag,
+		AgentID:                t.AgentID,
+		EnableDuplicateCheck:   t.EnableDuplicateCheck,
+		DuplicateCheckInterval: t.DuplicateCheckInterval,
+	}, map[string]any{
+		"title":       t.Title,
+		"description": t.Description,
+		"url":         t.URL,
+		"task_id":     t.TaskID,
+		"btn":         t.Btn,
+	}, nil
+}
+
+// 将指定成员收到的任务卡片更新为已点击clickedKey按钮的状态
+func (w *Wecom) UpdateTaskCard(ctx context.Context, agentID int, userids []string, taskID, clickedKey string) error {
+	_, err := w.post(WithAgentContext(ctx, w.agent(agentID)), "message/update_taskcard", map[string]any{
+		"userids":     userids,
+		"agentid":     w.agent(agentID),
+		"task_id":     taskID,
+		"clicked_key": clickedKey,
+	})
+	return err
+}
@@ -0,0 +1,112 @@
+package wecom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// 消息模板，各字段使用text/template语法
+type MessageTemplate struct {
+	// text、markdown或textcard
+	Msgtype string
+	// text及markdown的内容
+	Content string
+	// 以下为textcard的字段
+	Title       string
+	Description string
+	URL         string
+	Btntxt      string
+}
+
+// 消息的接收者
+type Recipients struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+}
+
+type parsedTemplate struct {
+	msgtype string
+	// 字段名到模板
+	fields map[string]*template.Template
+}
+
+type templates struct {
+	lock sync.RWMutex
+	m    map[string]*parsedTemplate
+}
+
+// 注册名为name的消息模板，已存在时覆盖
+func (w *Wecom) RegisterTemplate(name string, t MessageTemplate) error {
+	var fields map[string]string
+	switch t.Msgtype {
+	case "text", "markdown":
+		fields = map[string]string{"content": t.Content}
+	case "textcard":
+		fields = map[string]string{
+			"title":       t.Title,
+			"description": t.Description,
+			"url":         t.URL,
+			"btntxt":      t.Btntxt,
+		}
+	default:
+		return fmt.Errorf("wecom: unsupported template msgtype %q", t.Msgtype)
+	}
+
+	parsed := map[string]*template.Template{}
+	for k, v := range fields {
+		tmpl, err := template.New(name + "." + k).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return err
+		}
+		parsed[k] = tmpl
+	}
+	w.templates.lock.Lock()
+	defer w.templates.lock.Unlock()
+	if w.templates.m == nil {
+		w.templates.m = map[string]*parsedTemplate{}
+	}
+	w.templates.m[name] = &parsedTemplate{msgtype: t.Msgtype, fields: parsed}
+	return nil
+}
+
+// 使用data渲染名为name的模板后发送给r
+func (w *Wecom) SendTemplate(ctx context.Context, name string, data any, r Recipients) (*SendResult, error) {
+	w.templates.lock.RLock()
+	t := w.templates.m[name]
+	w.templates.lock.RUnlock()
+	if t == nil {
+		return nil, fmt.Errorf("wecom: template %q not registered", name)
+	}
+
+	fields := map[string]string{}
+	for k, tmpl := range t.fields {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, err
+		}
+		fields[k] = b.String()
+	}
+
+	switch t.msgtype {
+	case "text":
+		return w.Text(ctx, &TextInfo{Touser: r.Touser, Toparty: r.Toparty, Totag: r.Totag, AgentID: r.AgentID, Content: fields["content"]})
+	case "markdown":
+		return w.Markdown(ctx, &MarkdownInfo{Touser: r.Touser, Toparty: r.Toparty, Totag: r.Totag, AgentID: r.AgentID, Content: fields["content"]})
+	default:
+		return w.TextCard(ctx, &TextCardInfo{
+			Touser:      r.Touser,
+			Toparty:     r.Toparty,
+			Totag:       r.Totag,
+			AgentID:     r.AgentID,
+			Title:       fields["title"],
+			Description: fields["description"],
+			URL:         fields["url"],
+			Btntxt:      fields["btntxt"],
+		})
+	}
+}
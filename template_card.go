@@ -0,0 +1,221 @@
+package wecom
+
+import (
+	"context"
+	"errors"
+)
+
+type TemplateCardType string
+
+const (
+	TextNotice TemplateCardType = "text_notice"
+	NewsNotice TemplateCardType = "news_notice"
+	// 按钮交互型，需设置TaskID以便回调识别
+	ButtonInteraction TemplateCardType = "button_interaction"
+)
+
+type CardSource struct {
+	IconURL string `json:"icon_url,omitempty"`
+	Desc    string `json:"desc,omitempty"`
+	// 0默认灰色，1黑色，2红色，3绿色
+	DescColor int `json:"desc_color,omitempty"`
+}
+
+type CardMainTitle struct {
+	Title string `json:"title,omitempty"`
+	Desc  string `json:"desc,omitempty"`
+}
+
+// 关键数据样式，仅text_notice有效
+type CardEmphasisContent struct {
+	Title string `json:"title,omitempty"`
+	Desc  string `json:"desc,omitempty"`
+}
+
+type CardQuoteArea struct {
+	// 0或不填没有点击事件，1跳转url，2跳转小程序
+	Type      int    `json:"type,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Appid     string `json:"appid,omitempty"`
+	Pagepath  string `json:"pagepath,omitempty"`
+	Title     string `json:"title,omitempty"`
+	QuoteText string `json:"quote_text,omitempty"`
+}
+
+type CardHorizontalContent struct {
+	// 0或不填普通文本，1跳转url，2下载附件，3点击跳转成员详情
+	Type    int    `json:"type,omitempty"`
+	Keyname string `json:"keyname"`
+	Value   string `json:"value,omitempty"`
+	URL     string `json:"url,omitempty"`
+	MediaID string `json:"media_id,omitempty"`
+	Userid  string `json:"userid,omitempty"`
+}
+
+type CardJump struct {
+	// 0或不填不是链接，1跳转url，2跳转小程序
+	Type     int    `json:"type,omitempty"`
+	Title    string `json:"title"`
+	URL      string `json:"url,omitempty"`
+	Appid    string `json:"appid,omitempty"`
+	Pagepath string `json:"pagepath,omitempty"`
+}
+
+type CardAction struct {
+	// 1跳转url，2打开小程序
+	Type     int    `json:"type"`
+	URL      string `json:"url,omitempty"`
+	Appid    string `json:"appid,omitempty"`
+	Pagepath string `json:"pagepath,omitempty"`
+}
+
+// 左图右文样式，仅news_notice有效
+type CardImageTextArea struct {
+	Type     int    `json:"type,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Appid    string `json:"appid,omitempty"`
+	Pagepath string `json:"pagepath,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Desc     string `json:"desc,omitempty"`
+	ImageURL string `json:"image_url"`
+}
+
+// 图片样式，仅news_notice有效
+type CardImage struct {
+	URL         string  `json:"url"`
+	AspectRatio float64 `json:"aspect_ratio,omitempty"`
+}
+
+// 卡片二级垂直内容，仅news_notice有效
+type CardVerticalContent struct {
+	Title string `json:"title"`
+	Desc  string `json:"desc,omitempty"`
+}
+
+type CardSelectOption struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// 下拉式的选择器，仅button_interaction有效
+type CardButtonSelection struct {
+	QuestionKey string             `json:"question_key"`
+	Title       string             `json:"title,omitempty"`
+	OptionList  []CardSelectOption `json:"option_list"`
+	SelectedID  string             `json:"selected_id,omitempty"`
+}
+
+type CardButton struct {
+	// 0或不填回调点击事件，1跳转url
+	Type int    `json:"type,omitempty"`
+	Text string `json:"text"`
+	// 1~4，默认1
+	Style int `json:"style,omitempty"`
+	// 回调事件中的EventKey，Type为0时必填
+	Key string `json:"key,omitempty"`
+	URL string `json:"url,omitempty"`
+}
+
+type TemplateCard struct {
+	CardType              TemplateCardType        `json:"card_type"`
+	Source                *CardSource             `json:"source,omitempty"`
+	MainTitle             *CardMainTitle          `json:"main_title,omitempty"`
+	EmphasisContent       *CardEmphasisContent    `json:"emphasis_content,omitempty"`
+	QuoteArea             *CardQuoteArea          `json:"quote_area,omitempty"`
+	SubTitleText          string                  `json:"sub_title_text,omitempty"`
+	HorizontalContentList []CardHorizontalContent `json:"horizontal_content_list,omitempty"`
+	JumpList              []CardJump              `json:"jump_list,omitempty"`
+	CardAction            *CardAction             `json:"card_action,omitempty"`
+	ImageTextArea         *CardImageTextArea      `json:"image_text_area,omitempty"`
+	CardImage             *CardImage              `json:"card_image,omitempty"`
+	VerticalContentList   []CardVerticalContent   `json:"vertical_content_list,omitempty"`
+	ButtonSelection       *CardButtonSelection    `json:"button_selection,omitempty"`
+	ButtonList            []CardButton            `json:"button_list,omitempty"`
+	// 任务id，设置后可通过回调事件识别卡片，button_interaction必填
+	TaskID string `json:"task_id,omitempty"`
+}
+
+type TemplateCardInfo struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+	Card    *TemplateCard
+
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) TemplateCard(ctx context.Context, t *TemplateCardInfo) (*SendResult, error) {
+	m, body, err := templateCardMessage(t)
+	if err != nil {
+		return nil, err
+	}
+	return w.sendMessage(ctx, m, "template_card", body)
+}
+
+func templateCardMessage(t *TemplateCardInfo) (*message, any, error) {
+	if t.Card == nil {
+		return nil, nil, errors.New("template card is nil")
+	}
+	if t.Card.CardType == ButtonInteraction {
+		if t.Card.TaskID == "" {
+			return nil, nil, errors.New("button_interaction card requires task_id")
+		}
+		if len(t.Card.ButtonList) == 0 || len(t.Card.ButtonList) > 6 {
+			return nil, nil, errors.New("button_interaction card button_list count must be between 1 and 6")
+		}
+	}
+	return &message{
+		Touser:                 t.Touser,
+		Toparty:                t.Toparty,
+		Totag:                  t.Totag,
+		AgentID:                t.AgentID,
+		EnableDuplicateCheck:   t.EnableDuplicateCheck,
+		DuplicateCheckInterval: t.DuplicateCheckInterval,
+	}, t.Card, nil
+}
+
+type UpdateTemplateCardInfo struct {
+	Userids  []string
+	Partyids []int
+	Tagids   []int
+	// 更新整个任务接收人员
+	AtAll   bool
+	AgentID int
+	// 回调事件中的ResponseCode，72小时内有效且只能使用一次
+	ResponseCode string
+	// 将按钮替换为不可点击的文案，如“已处理”，与Card二选一
+	ReplaceName string
+	// 替换整张卡片，与ReplaceName二选一
+	Card *TemplateCard
+}
+
+func (w *Wecom) UpdateTemplateCard(ctx context.Context, u *UpdateTemplateCardInfo) error {
+	if u.ResponseCode == "" {
+		return errors.New("update template card requires response_code")
+	}
+	if (u.ReplaceName == "") == (u.Card == nil) {
+		return errors.New("exactly one of ReplaceName and Card must be set")
+	}
+
+	d := map[string]any{
+		"userids":       u.Userids,
+		"partyids":      u.Partyids,
+		"tagids":        u.Tagids,
+		"agentid":       w.agent(u.AgentID),
+		"response_code": u.ResponseCode,
+	}
+	if u.AtAll {
+		d["atall"] = 1
+	}
+	if u.Card != nil {
+		d["template_card"] = u.Card
+	} else {
+		d["button"] = map[string]string{
+			"replace_name": u.ReplaceName,
+		}
+	}
+	_, err := w.post(WithAgentContext(ctx, w.agent(u.AgentID)), "message/update_template_card", d)
+	return err
+}
@@ -0,0 +1,24 @@
+package wecom
+
+import (
+	"context"
+	"time"
+)
+
+// 默认的单次请求超时时间，http.DefaultClient本身不设超时
+const defaultTimeout = 10 * time.Second
+
+type timeoutKey struct{}
+
+// 为使用ctx的调用单独设置单次请求的超时时间，覆盖WithTimeout的设置，为0时不限制
+// 与context.WithTimeout不同，超时只针对每次HTTP请求，重试及刷新access_token的请求各自计时
+func WithTimeoutContext(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+func (w *Wecom) requestTimeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		return d
+	}
+	return w.timeout
+}
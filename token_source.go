@@ -0,0 +1,62 @@
+package wecom
+
+import "context"
+
+// TokenSource 提供调用接口使用的access_token
+// 默认实现使用New传入的corpid及corpsecret调用gettoken获取，也可以替换为服务商凭证、预先获取的access_token或测试替身
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSource可选实现该接口，access_token失效时调用Invalidate后重新调用Token
+// 未实现时access_token失效直接返回错误
+type TokenInvalidator interface {
+	Invalidate(ctx context.Context, token string)
+}
+
+// 使用ts提供的access_token，此时忽略New传入的corpsecret及WithAgent、WithTokenStore
+func WithTokenSource(ts TokenSource) Option {
+	return func(w *Wecom) {
+		w.tokenSource = ts
+	}
+}
+
+// 返回客户端使用的TokenSource，可供其他需要access_token的SDK共享
+func (w *Wecom) TokenSource() TokenSource {
+	return w.tokenSource
+}
+
+// 通过corpid及corpsecret获取access_token，支持WithAgent及WithTokenStore
+type corpTokenSource struct {
+	w *Wecom
+}
+
+func (s *corpTokenSource) Token(ctx context.Context) (string, error) {
+	return s.w.token(ctx, s.w.credentialFor(ctx))
+}
+
+func (s *corpTokenSource) Invalidate(ctx context.Context, token string) {
+	s.w.credentialFor(ctx).invalidate(token)
+}
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// 始终返回token，适用于由其他服务统一获取access_token的场景，token失效时直接返回错误
+func StaticTokenSource(token string) TokenSource {
+	return staticTokenSource(token)
+}
+
+func (w *Wecom) canRenew() bool {
+	_, ok := w.tokenSource.(TokenInvalidator)
+	return ok
+}
+
+// stale失效后获取新的access_token
+func (w *Wecom) renewToken(ctx context.Context, stale string) (string, error) {
+	w.tokenSource.(TokenInvalidator).Invalidate(ctx, stale)
+	return w.tokenSource.Token(ctx)
+}
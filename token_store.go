@@ -0,0 +1,140 @@
+package wecom
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 保存access_token，可用于多个进程或实例间共享，避免各自调用gettoken触发频率限制
+type TokenStore interface {
+	// 未找到或已过期时返回空token
+	Get(ctx context.Context, key string) (token string, ttl time.Duration, err error)
+	Set(ctx context.Context, key, token string, ttl time.Duration) error
+}
+
+func WithTokenStore(s TokenStore) Option {
+	return func(w *Wecom) {
+		w.tokenStore = s
+	}
+}
+
+// 同一corpid下不同应用的secret对应不同的access_token，key中只保存secret的摘要
+func (w *Wecom) tokenKey(c *credential) string {
+	h := sha1.Sum([]byte(c.secret))
+	return "wecom:access_token:" + w.corpid + ":" + hex.EncodeToString(h[:8])
+}
+
+// 优先使用TokenStore中未过期的access_token，否则重新获取
+// TokenStore中的access_token已确认失效时同样重新获取，并覆盖TokenStore中的值
+func (w *Wecom) loadAccessToken(ctx context.Context, c *credential) error {
+	if w.tokenStore != nil {
+		token, ttl, err := w.tokenStore.Get(ctx, w.tokenKey(c))
+		if err != nil {
+			w.logger.Printf("wecom: load access_token from store: %v", err)
+		}
+		if err == nil && token != "" && ttl > 0 && !c.isInvalid(token) {
+			c.set(token, ttl)
+			return nil
+		}
+	}
+	return w.getAccessToken(ctx, c)
+}
+
+type fileToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FileTokenStore 将access_token保存到本地文件，适用于cron等短生命周期的进程
+type FileTokenStore struct {
+	path string
+	lock sync.Mutex
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+func (f *FileTokenStore) read() (map[string]fileToken, error) {
+	m := map[string]fileToken{}
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return m, nil
+		}
+		return nil, err
+	}
+	if len(b) == 0 {
+		return m, nil
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (f *FileTokenStore) Get(_ context.Context, key string) (string, time.Duration, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	m, err := f.read()
+	if err != nil {
+		return "", 0, err
+	}
+	t, ok := m[key]
+	if !ok {
+		return "", 0, nil
+	}
+	ttl := time.Until(t.ExpiresAt)
+	if ttl <= 0 {
+		return "", 0, nil
+	}
+	return t.Token, ttl, nil
+}
+
+func (f *FileTokenStore) Set(_ context.Context, key, token string, ttl time.Duration) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	m, err := f.read()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for k, t := range m {
+		if !now.Before(t.ExpiresAt) {
+			delete(m, k)
+		}
+	}
+	m[key] = fileToken{Token: token, ExpiresAt: now.Add(ttl)}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免其他进程读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,59 @@
+package wecom
+
+import "unicode/utf8"
+
+// 截断后追加在内容末尾
+const truncatedSuffix = "…(truncated)"
+
+// 文本（2048字节）及markdown（4096字节）消息内容超长时不再返回ErrContentTooLong，
+// 而是在UTF-8字符边界处截断并追加“…(truncated)”，只发送一条消息
+// 同时开启WithSplitLongText时文本消息优先拆分
+func WithTruncate() Option {
+	return func(w *Wecom) {
+		w.truncate = true
+	}
+}
+
+// 返回截断后的消息体，未开启或不需要截断时原样返回
+func (w *Wecom) truncateContent(msgtype string, body any) any {
+	if !w.truncate {
+		return body
+	}
+	d, ok := body.(map[string]string)
+	if !ok {
+		return body
+	}
+	var max int
+	switch msgtype {
+	case "text":
+		max = maxTextBytes
+	case "markdown":
+		max = maxMarkdownBytes
+	default:
+		return body
+	}
+	if len(d["content"]) <= max {
+		return body
+	}
+	c := make(map[string]string, len(d))
+	for k, v := range d {
+		c[k] = v
+	}
+	c["content"] = TruncateToBytes(d["content"], max-len(truncatedSuffix)) + truncatedSuffix
+	return c
+}
+
+// 将s截断为不超过n字节，不会拆开多字节的UTF-8字符（如中文）
+// 企业微信的长度限制均按UTF-8编码的字节数计算，一个中文字符占3字节
+func TruncateToBytes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
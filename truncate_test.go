@@ -0,0 +1,56 @@
+package wecom
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateToBytes(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel"},
+		{"hello", 0, ""},
+		{"hello", -1, ""},
+		{"中文", 6, "中文"},
+		{"中文", 5, "中"},
+		{"中文", 3, "中"},
+		{"中文", 2, ""},
+		{"a中文", 4, "a中"},
+	}
+	for _, tt := range tests {
+		if got := TruncateToBytes(tt.s, tt.n); got != tt.want {
+			t.Errorf("TruncateToBytes(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateContent(t *testing.T) {
+	w := New("corpid", "secret", WithTruncate())
+	tests := []struct {
+		msgtype string
+		content string
+		max     int
+	}{
+		{"text", strings.Repeat("中", 1000), maxTextBytes},
+		{"markdown", strings.Repeat("中", 2000), maxMarkdownBytes},
+		{"text", "short", maxTextBytes},
+	}
+	for _, tt := range tests {
+		got := w.truncateContent(tt.msgtype, map[string]string{"content": tt.content}).(map[string]string)["content"]
+		if len(got) > tt.max || !utf8.ValidString(got) {
+			t.Errorf("%s: truncated to %d bytes, valid UTF-8 %v", tt.msgtype, len(got), utf8.ValidString(got))
+		}
+		if len(tt.content) <= tt.max && got != tt.content {
+			t.Errorf("%s: content within limit changed to %q", tt.msgtype, got)
+		}
+		if len(tt.content) > tt.max && !strings.HasSuffix(got, truncatedSuffix) {
+			t.Errorf("%s: truncated content does not end with %q", tt.msgtype, truncatedSuffix)
+		}
+	}
+}
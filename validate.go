@@ -0,0 +1,87 @@
+package wecom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 发送前的参数校验错误，返回的错误包含具体原因，可通过errors.Is判断
+var (
+	ErrContentTooLong    = errors.New("wecom: content too long")
+	ErrEmptyContent      = errors.New("wecom: content is empty")
+	ErrNoRecipients      = errors.New("wecom: touser, toparty and totag are all empty")
+	ErrTooManyRecipients = errors.New("wecom: too many recipients")
+	ErrAgentIDRequired   = errors.New("wecom: agentid is required")
+)
+
+// 按UTF-8编码的字节数计算
+const (
+	maxTextBytes     = 2048
+	maxMarkdownBytes = 4096
+	maxCardTitle     = 128
+	maxCardDesc      = 512
+)
+
+// 单次发送的接收者上限
+const (
+	maxTouser  = 1000
+	maxToparty = 100
+	maxTotag   = 100
+)
+
+// agentID为已使用默认值补全后的值
+func validateMessage(m *message, msgtype string, agentID int, body any) error {
+	if m.Touser == "" && m.Toparty == "" && m.Totag == "" {
+		return ErrNoRecipients
+	}
+	if err := validateRecipients("touser", m.Touser, maxTouser); err != nil {
+		return err
+	}
+	if err := validateRecipients("toparty", m.Toparty, maxToparty); err != nil {
+		return err
+	}
+	if err := validateRecipients("totag", m.Totag, maxTotag); err != nil {
+		return err
+	}
+	if agentID == 0 && msgtype != "miniprogram_notice" {
+		return ErrAgentIDRequired
+	}
+
+	d, ok := body.(map[string]string)
+	if !ok {
+		return nil
+	}
+	switch msgtype {
+	case "text":
+		return validateContent("text content", d["content"], maxTextBytes)
+	case "markdown":
+		return validateContent("markdown content", d["content"], maxMarkdownBytes)
+	case "textcard":
+		if err := validateContent("textcard title", d["title"], maxCardTitle); err != nil {
+			return err
+		}
+		return validateContent("textcard description", d["description"], maxCardDesc)
+	}
+	return nil
+}
+
+func validateRecipients(field, ids string, max int) error {
+	if ids == "" || ids == ToAll {
+		return nil
+	}
+	if n := strings.Count(ids, "|") + 1; n > max {
+		return fmt.Errorf("%w: %s has %d ids, limit %d", ErrTooManyRecipients, field, n, max)
+	}
+	return nil
+}
+
+func validateContent(field, s string, max int) error {
+	if s == "" {
+		return fmt.Errorf("%w: %s", ErrEmptyContent, field)
+	}
+	if len(s) > max {
+		return fmt.Errorf("%w: %s is %d bytes, limit %d", ErrContentTooLong, field, len(s), max)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package wecom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       *message
+		msgtype string
+		agentID int
+		body    any
+		want    error
+	}{
+		{"ok", &message{Touser: "a"}, "text", 1, map[string]string{"content": "hi"}, nil},
+		{"no recipients", &message{}, "text", 1, map[string]string{"content": "hi"}, ErrNoRecipients},
+		{"to all", &message{Touser: ToAll}, "text", 1, map[string]string{"content": "hi"}, nil},
+		{"too many users", &message{Touser: strings.Repeat("a|", 1000) + "a"}, "text", 1, map[string]string{"content": "hi"}, ErrTooManyRecipients},
+		{"too many parties", &message{Toparty: strings.Repeat("1|", 100) + "1"}, "text", 1, map[string]string{"content": "hi"}, ErrTooManyRecipients},
+		{"no agentid", &message{Touser: "a"}, "text", 0, map[string]string{"content": "hi"}, ErrAgentIDRequired},
+		{"miniprogram_notice without agentid", &message{Touser: "a"}, "miniprogram_notice", 0, map[string]any{}, nil},
+		{"empty text", &message{Touser: "a"}, "text", 1, map[string]string{"content": ""}, ErrEmptyContent},
+		{"text at limit", &message{Touser: "a"}, "text", 1, map[string]string{"content": strings.Repeat("a", maxTextBytes)}, nil},
+		{"text too long", &message{Touser: "a"}, "text", 1, map[string]string{"content": strings.Repeat("a", maxTextBytes+1)}, ErrContentTooLong},
+		{"markdown too long", &message{Touser: "a"}, "markdown", 1, map[string]string{"content": strings.Repeat("a", maxMarkdownBytes+1)}, ErrContentTooLong},
+		{"textcard title too long", &message{Touser: "a"}, "textcard", 1, map[string]string{"title": strings.Repeat("中", 43), "description": "d"}, ErrContentTooLong},
+		{"textcard without description", &message{Touser: "a"}, "textcard", 1, map[string]string{"title": "t"}, ErrEmptyContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMessage(tt.m, tt.msgtype, tt.agentID, tt.body)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("validateMessage() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,19 @@ package wecom
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"net/url"
-	"sync"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
 )
 
 // cspell: disable
@@ -20,45 +25,93 @@ type accessResp struct {
 	ExpiresIn   int    `json:"expires_in"`
 }
 
-type wecom struct {
-	corpid                string
-	corpsecret            string
-	accessToken           string
-	pushLock              *sync.Mutex
-	initLock              *sync.Mutex
-	isFirstAccessTokenErr bool
+// Wecom 企业微信应用的客户端，由New、NewFromEnv或NewFromConfig创建，可安全地在多个goroutine间共享
+// 仅需发送消息时可使用Client接口，便于在测试中替换
+type Wecom struct {
+	corpid string
+	// 默认应用的凭证，未通过WithAgent注册的应用均使用该凭证
+	credential *credential
+	// WithAgent注册的应用凭证
+	agents          map[int]*credential
+	maxTokenRetries int
+	allowToAll      bool
+	agentID         int
+	httpClient      *http.Client
+	baseURL         string
+	timeout         time.Duration
+	proxy           string
+	tokenStore      TokenStore
+	tokenSource     TokenSource
+	refreshGroup    singleflight.Group
+	retry           RetryPolicy
+	// 触发频率限制(45009)时的等待时间及最大重试次数
+	rateLimitDelay   time.Duration
+	rateLimitRetries int
+	limiter          *rate.Limiter
+	breaker          *breaker
+	suppressor       *suppressor
+	templates        templates
+	splitLongText    bool
+	truncate         bool
+	dryRun           bool
+	logger           Logger
+	slog             *slog.Logger
+	requestHooks     []RequestHook
+	responseHooks    []ResponseHook
+	metrics          Metrics
+	mediaCache       *mediaCache
+	jsapiTicket      jsapiTicket
 }
 
-func New(corpid, corpsecret string) *wecom {
-	return &wecom{
-		corpid:                corpid,
-		corpsecret:            corpsecret,
-		pushLock:              &sync.Mutex{},
-		initLock:              &sync.Mutex{},
-		isFirstAccessTokenErr: true,
+const defaultBaseURL = "https://qyapi.weixin.qq.com/cgi-bin/"
+
+// access_token提前刷新的时间，避免请求途中过期
+const tokenRefreshMargin = 5 * time.Minute
+
+func New(corpid, corpsecret string, opts ...Option) *Wecom {
+	w := &Wecom{
+		corpid:           corpid,
+		credential:       &credential{secret: corpsecret},
+		maxTokenRetries:  1,
+		rateLimitDelay:   time.Second,
+		rateLimitRetries: 3,
+		logger:           nopLogger{},
+		httpClient:       http.DefaultClient,
+		timeout:          defaultTimeout,
+		baseURL:          defaultBaseURL,
+	}
+	for _, opt := range opts {
+		opt(w)
 	}
+	if w.tokenSource == nil {
+		w.tokenSource = &corpTokenSource{w: w}
+	}
+	if w.proxy != "" {
+		if t, ok := proxyTransport(w.httpClient.Transport, w.proxy); ok {
+			c := *w.httpClient
+			c.Transport = t
+			w.httpClient = &c
+		} else {
+			w.logger.Printf("wecom: WithProxy ignored: http.Client.Transport %T is not *http.Transport", w.httpClient.Transport)
+		}
+	}
+	return w
 }
 
-func (w *wecom) getAccessToken() error {
-	reqUrl := "https://qyapi.weixin.qq.com/cgi-bin/gettoken"
+func (w *Wecom) getAccessToken(ctx context.Context, c *credential) error {
+	reqUrl := w.baseURL + "gettoken"
 	d := url.Values{
 		"corpid":     {w.corpid},
-		"corpsecret": {w.corpsecret},
+		"corpsecret": {c.secret},
 	}
 	reqUrl += "?" + d.Encode()
 
-	r, err := http.NewRequest(http.MethodPost, reqUrl, nil)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, reqUrl, nil)
 	if err != nil {
 		return err
 	}
 	r.Header.Add("accept", "application/json")
-	r2, err := http.DefaultClient.Do(r)
-	if err != nil {
-		return err
-	}
-	defer r2.Body.Close()
-
-	b, err := io.ReadAll(r2.Body)
+	b, err := w.do(r)
 	if err != nil {
 		return err
 	}
@@ -67,116 +120,400 @@ func (w *wecom) getAccessToken() error {
 		return err
 	}
 	if a.Errcode != 0 {
-		return errors.New(a.Errmsg)
+		return &Error{Errcode: a.Errcode, Errmsg: a.Errmsg}
 	}
 
-	w.accessToken = a.AccessToken
+	ttl := time.Duration(a.ExpiresIn)*time.Second - tokenRefreshMargin
+	c.set(a.AccessToken, ttl)
+	w.logger.Printf("wecom: access_token refreshed, expires in %ds", a.ExpiresIn)
+	if w.metrics != nil {
+		w.metrics.TokenRefreshed()
+	}
+	if w.tokenStore != nil {
+		// 写入失败不影响本次发送，下次仍会重新获取
+		if err := w.tokenStore.Set(ctx, w.tokenKey(c), a.AccessToken, ttl); err != nil {
+			w.logger.Printf("wecom: save access_token to store: %v", err)
+		}
+	}
 	return nil
 }
 
-func (w *wecom) send(getResp func() ([]byte, error)) ([]byte, error) {
-	err := func() error {
-		w.initLock.Lock()
-		defer w.initLock.Unlock()
-		if w.accessToken == "" {
-			err := w.getAccessToken()
-			if err != nil {
-				return err
-			}
-		}
-		return nil
-	}()
-	if err != nil {
-		return nil, err
+// 返回未过期的access_token，过期或尚未获取时先刷新
+func (w *Wecom) token(ctx context.Context, c *credential) (string, error) {
+	if token, ok := c.valid(); ok {
+		return token, nil
 	}
 
-	resp, err := getResp()
-	if err != nil {
-		return nil, err
+	if err := w.refreshAccessToken(ctx, c); err != nil {
+		return "", err
 	}
-	r := struct {
-		ErrCode int    `json:"errcode"`
-		ErrMsg  string `json:"errmsg"`
-	}{}
-	if err := json.Unmarshal(resp, &r); err != nil {
+	token, _ := c.get()
+	return token, nil
+}
+
+// 并发刷新access_token时只发起一次请求，其余调用者共享结果
+func (w *Wecom) refreshAccessToken(ctx context.Context, c *credential) error {
+	_, err, _ := w.refreshGroup.Do("token:"+c.secret, func() (any, error) {
+		return nil, w.loadAccessToken(ctx, c)
+	})
+	return err
+}
+
+// access_token无效或过期
+func isTokenErr(errcode int) bool {
+	return errcode == 42001 || errcode == 40014 || errcode == 41001
+}
+
+// getResp使用传入的access_token构造并发送请求
+// access_token失效时刷新后重试，最多重试maxTokenRetries次；临时性错误按RetryPolicy重试
+func (w *Wecom) send(ctx context.Context, getResp func(token string) ([]byte, error)) ([]byte, error) {
+	// 演练模式不发出请求，无需access_token
+	if w.dryRun {
+		return getResp("")
+	}
+	// gettoken请求不属于发送消息，不记录msgtype
+	tctx := withMsgtype(ctx, "")
+	token, err := w.tokenSource.Token(tctx)
+	if err != nil {
 		return nil, err
 	}
 
-	w.pushLock.Lock()
-	if r.ErrCode == 0 {
-		w.pushLock.Unlock()
-	} else if r.ErrCode == 42001 || r.ErrCode == 40014 || r.ErrCode == 41001 {
-		if w.isFirstAccessTokenErr {
-			w.isFirstAccessTokenErr = false
-			err := w.getAccessToken()
-			w.pushLock.Unlock()
-			if err != nil {
+	tokenRetries, rateLimitRetries, retries := 0, 0, 0
+	for {
+		resp, err := getResp(token)
+		if err == nil {
+			r := struct {
+				ErrCode int    `json:"errcode"`
+				ErrMsg  string `json:"errmsg"`
+			}{}
+			if err := json.Unmarshal(resp, &r); err != nil {
 				return nil, err
 			}
-			resp, err = w.send(getResp)
-			if err != nil {
-				return nil, err
+			if r.ErrCode == 0 {
+				return resp, nil
 			}
-		} else {
-			w.pushLock.Unlock()
-			w.isFirstAccessTokenErr = true
-			resp, err = w.send(getResp)
-			if err != nil {
-				return nil, err
+			if isTokenErr(r.ErrCode) && tokenRetries < w.maxTokenRetries && w.canRenew() {
+				tokenRetries++
+				w.logger.Printf("wecom: access_token invalid (errcode %d), refreshing", r.ErrCode)
+				token, err = w.renewToken(tctx, token)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if r.ErrCode == 45009 && rateLimitRetries < w.rateLimitRetries {
+				rateLimitRetries++
+				w.logger.Printf("wecom: api freq out of limit, retrying in %v", w.rateLimitDelay)
+				if err := sleep(ctx, w.rateLimitDelay); err != nil {
+					return nil, err
+				}
+				continue
 			}
+			err = &Error{Errcode: r.ErrCode, Errmsg: r.ErrMsg}
+		}
+
+		if !w.retry.shouldRetry(ctx, err, retries) {
+			return nil, err
 		}
+		w.logger.Printf("wecom: request failed, retrying (%d/%d): %v", retries+1, w.retry.MaxAttempts-1, err)
+		if err := w.retry.wait(ctx, retries); err != nil {
+			return nil, err
+		}
+		retries++
+	}
+}
+
+// 非2xx的HTTP响应
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "wecom: unexpected http status " + e.Status
+}
+
+func (w *Wecom) do(r *http.Request) ([]byte, error) {
+	_, b, err := w.doHeader(r)
+	return b, err
+}
+
+// 与do相同，同时返回响应头，用于下载文件等非JSON响应
+func (w *Wecom) doHeader(r *http.Request) (http.Header, []byte, error) {
+	if w.dryRun {
+		return w.dryRunRequest(r)
+	}
+	if err := w.breaker.allow(); err != nil {
+		return nil, nil, err
+	}
+	for _, h := range w.requestHooks {
+		h(r)
+	}
+	start := time.Now()
+	statusCode, header, b, err := w.roundTrip(r)
+	latency := time.Since(start)
+	// 调用者取消的请求不计入熔断
+	if r.Context().Err() == nil {
+		w.breaker.record(err)
 	} else {
-		w.pushLock.Unlock()
-		return nil, errors.New(r.ErrMsg)
+		w.breaker.abort()
+	}
+	if w.metrics != nil {
+		errcode, _, _ := errcodeOf(b)
+		w.metrics.ObserveRequest(RequestMetric{
+			Endpoint: endpoint(r),
+			Msgtype:  MsgtypeFromContext(r.Context()),
+			Errcode:  errcode,
+			Err:      err,
+			Duration: latency,
+		})
+	}
+	w.logCall(r, b, latency, err)
+	for _, h := range w.responseHooks {
+		h(&Response{Request: r, StatusCode: statusCode, Body: b, Latency: latency}, err)
 	}
+	return header, b, err
+}
 
-	return resp, nil
+func (w *Wecom) roundTrip(r *http.Request) (int, http.Header, []byte, error) {
+	if d := w.requestTimeout(r.Context()); d > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+	r2, err := w.httpClient.Do(r)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer r2.Body.Close()
+
+	b, err := io.ReadAll(r2.Body)
+	if err != nil {
+		return r2.StatusCode, r2.Header, nil, err
+	}
+	if r2.StatusCode < 200 || r2.StatusCode > 299 {
+		return r2.StatusCode, r2.Header, b, &StatusError{StatusCode: r2.StatusCode, Status: r2.Status}
+	}
+	return r2.StatusCode, r2.Header, b, nil
 }
 
-type TextInfo struct {
-	Touser  string
-	AgentID int
-	Content string
+func (w *Wecom) postJSON(ctx context.Context, url string, d any) ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Add("content-type", "application/json")
+	r.Header.Add("accept", "application/json")
+	return w.do(r)
 }
 
-func (w *wecom) Text(t *TextInfo) error {
-	buf := func() ([]byte, error) {
-		url := "https://qyapi.weixin.qq.com/cgi-bin/message/send?access_token=" + w.accessToken
-		d := map[string]any{
-			"touser":  t.Touser,
-			"msgtype": "text",
-			"agentid": t.AgentID,
-			"text": map[string]string{
-				"content": t.Content,
-			},
-			"safe": "0",
-		}
-		b, err := json.Marshal(d)
-		if err != nil {
-			return nil, err
+// 以access_token调用baseURL下的POST接口
+func (w *Wecom) post(ctx context.Context, path string, d any) ([]byte, error) {
+	buf := func(token string) ([]byte, error) {
+		url := w.baseURL + path + "?access_token=" + url.QueryEscape(token)
+		return w.postJSON(ctx, url, d)
+	}
+	return w.send(ctx, buf)
+}
+
+// 以access_token调用baseURL下的GET接口
+func (w *Wecom) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	buf := func(token string) ([]byte, error) {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
 		}
-		r, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+		q.Set("access_token", token)
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, w.baseURL+path+"?"+q.Encode(), nil)
 		if err != nil {
 			return nil, err
 		}
-		r.Header.Add("content-type", "application/json")
 		r.Header.Add("accept", "application/json")
-		r2, err := http.DefaultClient.Do(r)
-		if err != nil {
-			return nil, err
+		return w.do(r)
+	}
+	return w.send(ctx, buf)
+}
+
+// 发送应用消息的结果
+type SendResult struct {
+	// 消息id，用于撤回应用消息
+	MsgID string `json:"msgid"`
+	// 开启WithSplitLongText且内容被拆分时为各条消息的id，MsgID为第一条
+	PartMsgIDs []string `json:"-"`
+	// 不合法的userid，多个以“|”分隔，下同
+	InvalidUser  string `json:"invaliduser"`
+	InvalidParty string `json:"invalidparty"`
+	InvalidTag   string `json:"invalidtag"`
+	// 没有基础接口许可的userid
+	UnlicensedUser string `json:"unlicenseduser"`
+}
+
+// 是否存在未能送达的接收者
+func (r *SendResult) HasInvalid() bool {
+	return r.InvalidUser != "" || r.InvalidParty != "" || r.InvalidTag != "" || r.UnlicensedUser != ""
+}
+
+// agentID为0时使用WithAgentID设置的默认值
+func (w *Wecom) agent(agentID int) int {
+	if agentID == 0 {
+		return w.agentID
+	}
+	return agentID
+}
+
+// 向应用可见范围内的全部成员发送，需先调用AllowToAll(true)
+const ToAll = "@all"
+
+var ErrToAllNotAllowed = errors.New("sending to @all is not allowed, call AllowToAll(true) first")
+
+// 允许发送给@all，避免误发给全公司
+func (w *Wecom) AllowToAll(allow bool) {
+	w.allowToAll = allow
+}
+
+// 应用消息的公共字段
+type message struct {
+	// 成员ID、部门ID、标签ID列表，多个以“|”分隔
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+	// 是否保密消息，仅text、image、video、file、textcard、mpnews支持
+	Safe bool
+	// 是否开启id转译，开启后内容中的$userName=ID$、$departmentName=ID$会被替换为对应名称，仅text、textcard、news、mpnews支持
+	EnableIDTrans bool
+	// 是否开启重复消息检查，DuplicateCheckInterval秒内相同内容的消息不会重复发送
+	EnableDuplicateCheck bool
+	// 重复消息检查的时间间隔，单位秒，默认1800，最大不超过4小时
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) sendMessage(ctx context.Context, m *message, msgtype string, body any) (*SendResult, error) {
+	if m.Touser == ToAll && !w.allowToAll {
+		return nil, ErrToAllNotAllowed
+	}
+	if parts := w.splitLongContent(ctx, msgtype, body); parts != nil {
+		return w.sendParts(ctx, m, parts)
+	}
+	body = w.truncateContent(msgtype, body)
+	if err := validateMessage(m, msgtype, w.agent(m.AgentID), body); err != nil {
+		return nil, err
+	}
+	body, undo, err := w.suppress(m, msgtype, body)
+	if err != nil {
+		return nil, err
+	}
+	d := buildPayload(m, msgtype, body)
+	if msgtype != "miniprogram_notice" {
+		d["agentid"] = w.agent(m.AgentID)
+	}
+	agentID, _ := d["agentid"].(int)
+	if c := captureFrom(ctx); c != nil {
+		c.msgtype, c.agentID, c.payload = msgtype, agentID, d
+		return nil, errCaptured
+	}
+	r, err := w.postMessage(ctx, msgtype, agentID, d)
+	if err != nil {
+		// 未能发送的消息不计入抑制，调用者在window内重试时仍会发送
+		undo()
+		return nil, err
+	}
+	return r, nil
+}
+
+// 构造message/send的请求体，AgentID为0时不包含agentid
+func buildPayload(m *message, msgtype string, body any) map[string]any {
+	d := map[string]any{
+		"touser":  m.Touser,
+		"toparty": m.Toparty,
+		"totag":   m.Totag,
+		"msgtype": msgtype,
+		msgtype:   body,
+	}
+	// miniprogram_notice不需要agentid
+	if msgtype != "miniprogram_notice" && m.AgentID != 0 {
+		d["agentid"] = m.AgentID
+	}
+	if m.Safe {
+		d["safe"] = 1
+	}
+	if m.EnableIDTrans {
+		d["enable_id_trans"] = 1
+	}
+	if m.EnableDuplicateCheck {
+		d["enable_duplicate_check"] = 1
+		if m.DuplicateCheckInterval > 0 {
+			d["duplicate_check_interval"] = m.DuplicateCheckInterval
 		}
-		defer r2.Body.Close()
+	}
+	return d
+}
 
-		b2, err := io.ReadAll(r2.Body)
-		if err != nil {
+// 发送已构造好的应用消息，agentID用于选择对应应用的access_token
+func (w *Wecom) postMessage(ctx context.Context, msgtype string, agentID int, d any) (*SendResult, error) {
+	if w.limiter != nil && !w.dryRun {
+		if err := w.limiter.Wait(ctx); err != nil {
 			return nil, err
 		}
-		return b2, nil
 	}
-	if _, err := w.send(buf); err != nil {
-		return err
+	if agentID != 0 {
+		ctx = WithAgentContext(ctx, agentID)
+	}
+	b, err := w.post(withMsgtype(ctx, msgtype), "message/send", d)
+	w.observeMessage(msgtype, err)
+	if err != nil {
+		return nil, err
+	}
+	r := &SendResult{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// 撤回24小时内通过发送应用消息接口推送的消息
+func (w *Wecom) Recall(ctx context.Context, msgid string) error {
+	_, err := w.post(ctx, "message/recall", map[string]string{
+		"msgid": msgid,
+	})
+	return err
+}
+
+type TextInfo struct {
+	Touser  string
+	Toparty string
+	Totag   string
+	AgentID int
+	Safe    bool
+	Content string
+
+	EnableIDTrans          bool
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+}
+
+func (w *Wecom) Text(ctx context.Context, t *TextInfo) (*SendResult, error) {
+	m, body := textMessage(t)
+	return w.sendMessage(ctx, m, "text", body)
+}
+
+func textMessage(t *TextInfo) (*message, any) {
+	return &message{
+		Touser:                 t.Touser,
+		Toparty:                t.Toparty,
+		Totag:                  t.Totag,
+		AgentID:                t.AgentID,
+		Safe:                   t.Safe,
+		EnableIDTrans:          t.EnableIDTrans,
+		EnableDuplicateCheck:   t.EnableDuplicateCheck,
+		DuplicateCheckInterval: t.DuplicateCheckInterval,
+	}, map[string]string{
+		"content": t.Content,
 	}
-	return nil
 }
 
 type Filetype string
@@ -188,111 +525,157 @@ const (
 	FILE  Filetype = "file"
 )
 
-func (w *wecom) getMediaID(content []byte, filetype Filetype, filename string) (string, error) {
-	buf := func() ([]byte, error) {
-		url := fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/media/upload?access_token=%v&type=%v", w.accessToken, filetype)
-		b := &bytes.Buffer{}
-		writer := multipart.NewWriter(b)
-		part, err := writer.CreateFormFile("media", filename)
-		if err != nil {
-			return nil, err
-		}
-		if _, err := io.Copy(part, bytes.NewReader(content)); err != nil {
-			return nil, err
-		}
-		if err := writer.Close(); err != nil {
-			return nil, err
-		}
-		r, err := http.NewRequest(http.MethodPost, url, b)
-		if err != nil {
-			return nil, err
-		}
-		r.Header.Add("content-type", writer.FormDataContentType())
-		r.Header.Add("accept", "application/json")
-		r2, err := http.DefaultClient.Do(r)
-		if err != nil {
-			return nil, err
-		}
-		defer r2.Body.Close()
-
-		b2, err := io.ReadAll(r2.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		return b2, nil
+// 以multipart/form-data上传文件，表单字段名为media
+func (w *Wecom) upload(ctx context.Context, url string, content []byte, filename string) ([]byte, error) {
+	b := &bytes.Buffer{}
+	writer := multipart.NewWriter(b)
+	part, err := writer.CreateFormFile("media", filename)
+	if err != nil {
+		return nil, err
 	}
-
-	b, err := w.send(buf)
+	if _, err := io.Copy(part, bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, url, b)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	r.Header.Add("content-type", writer.FormDataContentType())
+	r.Header.Add("accept", "application/json")
+	return w.do(r)
+}
+
+func (w *Wecom) getMediaID(ctx context.Context, content []byte, filetype Filetype, filename string) (string, error) {
+	if w.mediaCache == nil {
+		return w.UploadMedia(ctx, bytes.NewReader(content), int64(len(content)), filetype, filename)
 	}
 
-	m := map[string]any{}
-	if err := json.Unmarshal(b, &m); err != nil {
+	key := mediaCacheKey(content, filetype, filename)
+	if m, ok := w.mediaCache.get(key); ok {
+		return m, nil
+	}
+	m, err := w.UploadMedia(ctx, bytes.NewReader(content), int64(len(content)), filetype, filename)
+	if err != nil {
 		return "", err
 	}
-	return m["media_id"].(string), nil
+	w.mediaCache.set(key, m)
+	return m, nil
 }
 
 type FileInfo struct {
 	Touser   string
+	Toparty  string
+	Totag    string
 	AgentID  int
+	Safe     bool
 	Content  []byte
 	Filetype Filetype
 	Filename string
 
 	// 仅VIDEO有效
+	//
+	// Deprecated: 发送视频请使用Video
 	Title string
 	// 仅VIDEO有效
+	//
+	// Deprecated: 发送视频请使用Video
 	Description string
+
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
 }
 
-func (w *wecom) File(f *FileInfo) error {
-	m, err := w.getMediaID(f.Content, f.Filetype, f.Filename)
+func (w *Wecom) File(ctx context.Context, f *FileInfo) (*SendResult, error) {
+	m, err := w.getMediaID(ctx, f.Content, f.Filetype, f.Filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	buf := func() ([]byte, error) {
-		url := "https://qyapi.weixin.qq.com/cgi-bin/message/send?access_token=" + w.accessToken
-		m := map[string]any{
-			"touser":  f.Touser,
-			"msgtype": f.Filetype,
-			"agentid": f.AgentID,
-			string(f.Filetype): map[string]string{
-				"media_id":    m,
-				"title":       f.Title,
-				"description": f.Description,
-			},
-			"safe": 0,
+	return w.sendMessage(ctx, &message{
+		Touser:                 f.Touser,
+		Toparty:                f.Toparty,
+		Totag:                  f.Totag,
+		AgentID:                f.AgentID,
+		Safe:                   f.Safe,
+		EnableDuplicateCheck:   f.EnableDuplicateCheck,
+		DuplicateCheckInterval: f.DuplicateCheckInterval,
+	}, string(f.Filetype), map[string]string{
+		"media_id":    m,
+		"title":       f.Title,
+		"description": f.Description,
+	})
+}
+
+// 以access_token调用baseURL下的任意接口，供子包及尚未封装的接口使用
+// path为cgi-bin之后的部分，如"user/get"；body不为nil时以JSON编码作为请求体
+// out不为nil时将响应解码到out，可传入*json.RawMessage获取原始响应
+// 与其他接口一样会在access_token失效时刷新重试，并按RetryPolicy重试临时性错误，errcode不为0时返回*Error
+func (w *Wecom) Do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	path = strings.TrimPrefix(path, "/")
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return err
 		}
-		b, err := json.Marshal(m)
-		if err != nil {
-			return nil, err
+	}
+	buf := func(token string) ([]byte, error) {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
 		}
-		r, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
-		if err != nil {
-			return nil, err
+		q.Set("access_token", token)
+		var rb io.Reader
+		if b != nil {
+			rb = bytes.NewReader(b)
 		}
-		r.Header.Add("content-type", "application/json")
-		r.Header.Add("accept", "application/json")
-
-		r2, err := http.DefaultClient.Do(r)
+		r, err := http.NewRequestWithContext(ctx, method, w.baseURL+path+"?"+q.Encode(), rb)
 		if err != nil {
 			return nil, err
 		}
-		defer r2.Body.Close()
-
-		body, err := io.ReadAll(r2.Body)
-		if err != nil {
-			return nil, err
+		if b != nil {
+			r.Header.Add("content-type", "application/json")
 		}
-		return body, nil
+		r.Header.Add("accept", "application/json")
+		return w.do(r)
 	}
-
-	if _, err := w.send(buf); err != nil {
+	resp, err := w.send(ctx, buf)
+	if err != nil || out == nil {
 		return err
 	}
-	return nil
+	return json.Unmarshal(resp, out)
+}
+
+// 发送自行构造的应用消息，body为message/send的完整请求体，可用于本库尚未支持的消息类型
+// body为map[string]any时自动补全msgtype，agentid缺失时使用WithAgentID设置的默认值
+func (w *Wecom) SendRaw(ctx context.Context, msgtype string, body any) (*SendResult, error) {
+	agentID := 0
+	if d, ok := body.(map[string]any); ok {
+		if d["touser"] == ToAll && !w.allowToAll {
+			return nil, ErrToAllNotAllowed
+		}
+		c := make(map[string]any, len(d)+2)
+		for k, v := range d {
+			c[k] = v
+		}
+		c["msgtype"] = msgtype
+		if _, ok := c["agentid"]; !ok && msgtype != "miniprogram_notice" {
+			c["agentid"] = w.agent(0)
+		}
+		// 经过JSON序列化的请求体中agentid为float64
+		switch id := c["agentid"].(type) {
+		case int:
+			agentID = id
+		case float64:
+			agentID = int(id)
+		case json.Number:
+			n, _ := id.Int64()
+			agentID = int(n)
+		}
+		body = c
+	}
+	return w.postMessage(ctx, msgtype, agentID, body)
 }
@@ -0,0 +1,197 @@
+package wecom
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jzksnsjswkw/wecom-push/wecomtest"
+)
+
+func newTestClient(t *testing.T, opts ...Option) (*Wecom, *wecomtest.Server) {
+	t.Helper()
+	s := wecomtest.NewServer()
+	t.Cleanup(s.Close)
+	opts = append([]Option{WithBaseURL(s.BaseURL()), WithAgentID(1000002)}, opts...)
+	return New("corpid", "secret", opts...), s
+}
+
+func sendText(w *Wecom) error {
+	_, err := w.Text(context.Background(), &TextInfo{Touser: "a", Content: "hello"})
+	return err
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		fail     func(s *wecomtest.Server)
+		errcode  int
+		status   int
+		messages int
+	}{
+		{
+			name:     "rate limited then ok",
+			opts:     []Option{WithRateLimitRetry(time.Millisecond, 3)},
+			fail:     func(s *wecomtest.Server) { s.FailNext("message/send", 45009); s.FailNext("message/send", 45009) },
+			messages: 1,
+		},
+		{
+			name: "rate limit retries exhausted",
+			opts: []Option{WithRateLimitRetry(time.Millisecond, 1)},
+			fail: func(s *wecomtest.Server) {
+				s.FailNext("message/send", 45009)
+				s.FailNext("message/send", 45009)
+			},
+			errcode: 45009,
+		},
+		{
+			name:    "rate limit retry disabled",
+			opts:    []Option{WithRateLimitRetry(time.Millisecond, 0)},
+			fail:    func(s *wecomtest.Server) { s.FailNext("message/send", 45009) },
+			errcode: 45009,
+		},
+		{
+			name:     "system busy retried",
+			opts:     []Option{WithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})},
+			fail:     func(s *wecomtest.Server) { s.FailNext("message/send", -1) },
+			messages: 1,
+		},
+		{
+			name:     "bad gateway retried",
+			opts:     []Option{WithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})},
+			fail:     func(s *wecomtest.Server) { s.FailNextStatus("message/send", http.StatusBadGateway) },
+			messages: 1,
+		},
+		{
+			name:   "bad gateway without retry",
+			fail:   func(s *wecomtest.Server) { s.FailNextStatus("message/send", http.StatusBadGateway) },
+			status: http.StatusBadGateway,
+		},
+		{
+			name:    "invalid parameter not retried",
+			opts:    []Option{WithRetry(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})},
+			fail:    func(s *wecomtest.Server) { s.FailNext("message/send", 40003); s.FailNext("message/send", 40003) },
+			errcode: 40003,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, s := newTestClient(t, tt.opts...)
+			tt.fail(s)
+			err := sendText(w)
+			switch {
+			case tt.status != 0:
+				var se *StatusError
+				if !errors.As(err, &se) || se.StatusCode != tt.status {
+					t.Fatalf("send error = %v, want http status %d", err, tt.status)
+				}
+			case tt.errcode == 0:
+				if err != nil {
+					t.Fatalf("send: %v", err)
+				}
+			default:
+				var e *Error
+				if !errors.As(err, &e) || e.Errcode != tt.errcode {
+					t.Fatalf("send error = %v, want errcode %d", err, tt.errcode)
+				}
+			}
+			if n := len(s.Messages()); n != tt.messages {
+				t.Errorf("server received %d messages, want %d", n, tt.messages)
+			}
+		})
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []Option
+		expire        func(s *wecomtest.Server)
+		wantErrcode   int
+		tokenRequests int
+	}{
+		{
+			name:          "cached token reused",
+			expire:        func(*wecomtest.Server) {},
+			tokenRequests: 1,
+		},
+		{
+			name:          "expired token refreshed",
+			expire:        (*wecomtest.Server).ExpireToken,
+			tokenRequests: 2,
+		},
+		{
+			name:          "token errcode refreshed",
+			expire:        func(s *wecomtest.Server) { s.FailNext("message/send", 40014) },
+			tokenRequests: 2,
+		},
+		{
+			name: "refresh retries exhausted",
+			expire: func(s *wecomtest.Server) {
+				s.FailNext("message/send", 42001)
+				s.FailNext("message/send", 42001)
+			},
+			wantErrcode:   42001,
+			tokenRequests: 2,
+		},
+		{
+			name:          "refresh disabled",
+			opts:          []Option{WithMaxTokenRetries(0)},
+			expire:        (*wecomtest.Server).ExpireToken,
+			wantErrcode:   42001,
+			tokenRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, s := newTestClient(t, tt.opts...)
+			if err := sendText(w); err != nil {
+				t.Fatalf("first send: %v", err)
+			}
+			tt.expire(s)
+			err := sendText(w)
+			if tt.wantErrcode == 0 && err != nil {
+				t.Fatalf("second send: %v", err)
+			}
+			if tt.wantErrcode != 0 {
+				var e *Error
+				if !errors.As(err, &e) || e.Errcode != tt.wantErrcode {
+					t.Fatalf("second send error = %v, want errcode %d", err, tt.wantErrcode)
+				}
+			}
+			if n := s.TokenRequests(); n != tt.tokenRequests {
+				t.Errorf("gettoken called %d times, want %d", n, tt.tokenRequests)
+			}
+		})
+	}
+}
+
+func TestSendLongText(t *testing.T) {
+	long := strings.Repeat("a", maxTextBytes+100)
+	tests := []struct {
+		name     string
+		opts     []Option
+		want     error
+		messages int
+	}{
+		{"rejected", nil, ErrContentTooLong, 0},
+		{"truncated", []Option{WithTruncate()}, nil, 1},
+		{"split", []Option{WithSplitLongText()}, nil, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, s := newTestClient(t, tt.opts...)
+			_, err := w.Text(context.Background(), &TextInfo{Touser: "a", Content: long})
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("send error = %v, want %v", err, tt.want)
+			}
+			if n := len(s.Messages()); n != tt.messages {
+				t.Errorf("server received %d messages, want %d", n, tt.messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Package wecomlogrus 提供logrus.Hook，将Error及以上级别的日志推送到企业微信
+package wecomlogrus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/jzksnsjswkw/wecom-push"
+	"github.com/jzksnsjswkw/wecom-push/internal/logpush"
+)
+
+type Hook struct {
+	send     func(ctx context.Context, content string) error
+	markdown bool
+	levels   []logrus.Level
+	limiter  *logpush.Limiter
+}
+
+type Option func(*Hook)
+
+// 触发推送的级别，默认为Error、Fatal及Panic
+func WithLevels(levels ...logrus.Level) Option {
+	return func(h *Hook) {
+		h.levels = levels
+	}
+}
+
+// 限制每分钟推送的条数，默认10条
+func WithPerMinute(n int) Option {
+	return func(h *Hook) {
+		h.limiter = logpush.NewLimiter(n)
+	}
+}
+
+func newHook(send func(ctx context.Context, content string) error, markdown bool, opts []Option) *Hook {
+	h := &Hook{
+		send:     send,
+		markdown: markdown,
+		levels:   []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel},
+		limiter:  logpush.NewLimiter(logpush.DefaultPerMinute),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// 以markdown消息推送，n可以是*wecom.Bot或客户端Notifier方法的返回值
+func NewHook(n wecom.Notifier, opts ...Option) *Hook {
+	return newHook(n.Notify, true, opts)
+}
+
+// 以文本消息推送，如
+//
+//	wecomlogrus.NewTextHook(func(ctx context.Context, content string) error {
+//		_, err := w.Text(ctx, &wecom.TextInfo{Touser: "zhangsan", Content: content})
+//		return err
+//	})
+func NewTextHook(send func(ctx context.Context, content string) error, opts ...Option) *Hook {
+	return newHook(send, false, opts)
+}
+
+func (h *Hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Error级别在后台推送；Fatal及Panic级别同步推送，避免进程退出前未送达
+func (h *Hook) Fire(e *logrus.Entry) error {
+	ok, dropped := h.limiter.Allow()
+	if !ok {
+		return nil
+	}
+
+	content := h.format(e, dropped)
+	if e.Level <= logrus.FatalLevel {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return h.send(ctx, content)
+	}
+	go func() {
+		_ = h.send(context.Background(), content)
+	}()
+	return nil
+}
+
+func (h *Hook) format(e *logrus.Entry, dropped int) string {
+	var b strings.Builder
+	level := strings.ToUpper(e.Level.String())
+	if h.markdown {
+		fmt.Fprintf(&b, "<font color=\"warning\">**%s**</font> %s\n", level, e.Message)
+		fmt.Fprintf(&b, "> 时间：%s\n", e.Time.Format(time.DateTime))
+	} else {
+		fmt.Fprintf(&b, "[%s] %s\n时间：%s\n", level, e.Message, e.Time.Format(time.DateTime))
+	}
+	keys := make([]string, 0, len(e.Data))
+	for k := range e.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if h.markdown {
+			b.WriteString("> ")
+		}
+		fmt.Fprintf(&b, "%s：%v\n", k, e.Data[k])
+	}
+	return logpush.Content(strings.TrimRight(b.String(), "\n"), dropped)
+}
@@ -0,0 +1,146 @@
+// Package wecomslog 提供slog.Handler，将达到指定级别的日志以markdown消息推送到企业微信
+package wecomslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jzksnsjswkw/wecom-push"
+	"github.com/jzksnsjswkw/wecom-push/internal/logpush"
+)
+
+type Options struct {
+	// 推送的最低级别，默认为slog.LevelError
+	Level slog.Leveler
+	// 每分钟最多推送的条数，默认为10，超出的日志被丢弃并在下一条消息中注明丢弃的数量
+	PerMinute int
+	// 不为nil时所有日志同时交给Next处理，如输出到控制台的slog.TextHandler
+	Next slog.Handler
+}
+
+// 各Handler共享的状态
+type state struct {
+	n       wecom.Notifier
+	limiter *logpush.Limiter
+}
+
+type Handler struct {
+	s     *state
+	level slog.Leveler
+	next  slog.Handler
+	// WithAttrs添加的属性，已带有分组前缀
+	attrs []slog.Attr
+	group string
+}
+
+// n可以是*wecom.Bot或wecom.New返回的客户端的Notifier方法的返回值，推送在后台进行，不阻塞日志调用
+func NewHandler(n wecom.Notifier, opts *Options) *Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	level := opts.Level
+	if level == nil {
+		level = slog.LevelError
+	}
+	return &Handler{
+		s:     &state{n: n, limiter: logpush.NewLimiter(opts.PerMinute)},
+		level: level,
+		next:  opts.Next,
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= h.level.Level() || (h.next != nil && h.next.Enabled(ctx, l))
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if h.next != nil && h.next.Enabled(ctx, r.Level) {
+		if err := h.next.Handle(ctx, r); err != nil {
+			return err
+		}
+	}
+	if r.Level < h.level.Level() {
+		return nil
+	}
+
+	ok, dropped := h.s.limiter.Allow()
+	if !ok {
+		return nil
+	}
+
+	content := h.format(r, dropped)
+	go func() {
+		// 推送失败时无法再通过日志报告，直接忽略
+		_ = h.s.n.Notify(context.Background(), content)
+	}()
+	return nil
+}
+
+func (h *Handler) format(r slog.Record, dropped int) string {
+	var b strings.Builder
+	color := "info"
+	switch {
+	case r.Level >= slog.LevelError:
+		color = "warning"
+	case r.Level >= slog.LevelWarn:
+		color = "comment"
+	}
+	fmt.Fprintf(&b, "<font color=\"%s\">**%s**</font> %s\n", color, r.Level, r.Message)
+	fmt.Fprintf(&b, "> 时间：%s\n", r.Time.Format(time.DateTime))
+	for _, a := range h.attrs {
+		writeAttr(&b, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.group, a)
+		return true
+	})
+	return logpush.Content(b.String(), dropped)
+}
+
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		p := prefix
+		if a.Key != "" {
+			p += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, p, ga)
+		}
+		return
+	}
+	fmt.Fprintf(b, "> %s%s：%s\n", prefix, a.Key, a.Value)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		if h.group != "" {
+			a = slog.Group(strings.TrimSuffix(h.group, "."), a)
+		}
+		h2.attrs = append(h2.attrs, a)
+	}
+	if h.next != nil {
+		h2.next = h.next.WithAttrs(attrs)
+	}
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group = h.group + name + "."
+	if h.next != nil {
+		h2.next = h.next.WithGroup(name)
+	}
+	return &h2
+}
@@ -0,0 +1,254 @@
+package wecomtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"unicode/utf8"
+)
+
+type Mode int
+
+const (
+	// 请求真实接口并记录
+	ModeRecord Mode = iota
+	// 从记录中返回响应，不发出任何请求
+	ModeReplay
+)
+
+// 脱敏后的替换值
+const Redacted = "REDACTED"
+
+// 需要脱敏的查询参数及JSON字段
+var redactKeys = map[string]bool{
+	"access_token":       true,
+	"corpsecret":         true,
+	"suite_secret":       true,
+	"suite_ticket":       true,
+	"suite_access_token": true,
+	"permanent_code":     true,
+	"jsapi_ticket":       true,
+	"ticket":             true,
+}
+
+// 一次请求及其响应
+type Interaction struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// 非UTF-8内容（如上传的文件）不记录
+	RequestBody  string `json:"request_body,omitempty"`
+	StatusCode   int    `json:"status_code"`
+	ContentType  string `json:"content_type,omitempty"`
+	ResponseBody string `json:"response_body"`
+}
+
+// Recorder 录制/回放接口调用的http.RoundTripper，记录中的access_token、corpsecret等均已脱敏
+//
+//	r, err := wecomtest.NewRecorder("testdata/send_text.json", wecomtest.ModeReplay, nil)
+//	w := wecom.New(corpid, secret, wecom.WithHTTPClient(&http.Client{Transport: r}))
+//
+// 录制模式下需调用Save写入文件
+type Recorder struct {
+	path string
+	mode Mode
+	next http.RoundTripper
+
+	lock         sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+var _ http.RoundTripper = (*Recorder)(nil)
+
+// next为录制模式下实际发出请求的RoundTripper，为nil时使用http.DefaultTransport
+func NewRecorder(path string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{path: path, mode: mode, next: next}
+	if mode == ModeReplay {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &r.interactions); err != nil {
+			return nil, fmt.Errorf("wecomtest: parse %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+	u := redactURL(req.URL)
+
+	if r.mode == ModeReplay {
+		return r.replay(req, u)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	i := Interaction{
+		Method:       req.Method,
+		URL:          u,
+		StatusCode:   resp.StatusCode,
+		ContentType:  resp.Header.Get("content-type"),
+		ResponseBody: string(redactJSON(b)),
+	}
+	if utf8.Valid(body) {
+		i.RequestBody = string(redactJSON(body))
+	}
+	r.lock.Lock()
+	r.interactions = append(r.interactions, i)
+	r.lock.Unlock()
+	return resp, nil
+}
+
+// 按请求方法和脱敏后的URL依次匹配尚未使用的记录
+func (r *Recorder) replay(req *http.Request, u string) (*http.Response, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for n, i := range r.interactions {
+		if r.used[n] || i.Method != req.Method || i.URL != u {
+			continue
+		}
+		r.used[n] = true
+		h := http.Header{}
+		if i.ContentType != "" {
+			h.Set("content-type", i.ContentType)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.StatusCode, http.StatusText(i.StatusCode)),
+			StatusCode:    i.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        h,
+			Body:          io.NopCloser(bytes.NewBufferString(i.ResponseBody)),
+			ContentLength: int64(len(i.ResponseBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("wecomtest: no recorded interaction for %s %s", req.Method, u)
+}
+
+// 将录制的记录写入文件，回放模式下不做任何操作
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.lock.Lock()
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(b, '\n'), 0o644)
+}
+
+// 已录制或已回放的记录
+func (r *Recorder) Interactions() []Interaction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.mode == ModeRecord {
+		return append([]Interaction(nil), r.interactions...)
+	}
+	var s []Interaction
+	for n, i := range r.interactions {
+		if r.used[n] {
+			s = append(s, i)
+		}
+	}
+	return s
+}
+
+// 回放记录全部用完时返回nil
+func (r *Recorder) Unused() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var errs []error
+	for n, i := range r.interactions {
+		if r.mode == ModeReplay && !r.used[n] {
+			errs = append(errs, fmt.Errorf("wecomtest: unused interaction %s %s", i.Method, i.URL))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	for k := range q {
+		if redactKeys[k] {
+			q.Set(k, Redacted)
+		}
+	}
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+// 非JSON内容原样返回
+func redactJSON(b []byte) []byte {
+	var v any
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return b
+	}
+	if !redactValue(v) {
+		return b
+	}
+	r, err := json.Marshal(v)
+	if err != nil {
+		return b
+	}
+	return r
+}
+
+// 返回是否修改了v
+func redactValue(v any) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if _, ok := e.(string); ok && redactKeys[k] {
+				v[k] = Redacted
+				changed = true
+			} else if redactValue(e) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, e := range v {
+			if redactValue(e) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,204 @@
+// Package wecomtest 提供基于httptest的模拟企业微信服务端，用于端到端测试重试及access_token过期等逻辑；
+// 以及录制/回放真实接口调用的Recorder
+//
+//	s := wecomtest.NewServer()
+//	defer s.Close()
+//	w := wecom.New("corpid", "secret", wecom.WithBaseURL(s.BaseURL()))
+//	s.FailNext("message/send", 45009)
+package wecomtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// 上传的临时素材
+type Upload struct {
+	Type     string
+	Filename string
+	Content  []byte
+}
+
+// 预设的失败响应，status不为0时返回该HTTP状态码，否则返回errcode
+type failure struct {
+	status  int
+	errcode int
+}
+
+type Server struct {
+	*httptest.Server
+
+	lock          sync.Mutex
+	token         string
+	tokenRequests int
+	messages      []json.RawMessage
+	uploads       []Upload
+	failures      map[string][]failure
+}
+
+func NewServer() *Server {
+	s := &Server{failures: map[string][]failure{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cgi-bin/gettoken", s.gettoken)
+	mux.HandleFunc("/cgi-bin/message/send", s.messageSend)
+	mux.HandleFunc("/cgi-bin/media/upload", s.mediaUpload)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// 用于wecom.WithBaseURL
+func (s *Server) BaseURL() string {
+	return s.URL + "/cgi-bin/"
+}
+
+// 使endpoint（如message/send、gettoken）的下一次请求返回errcode，多次调用依次生效
+func (s *Server) FailNext(endpoint string, errcode int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failures[endpoint] = append(s.failures[endpoint], failure{errcode: errcode})
+}
+
+// 使endpoint的下一次请求返回HTTP状态码status，如502
+func (s *Server) FailNextStatus(endpoint string, status int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failures[endpoint] = append(s.failures[endpoint], failure{status: status})
+}
+
+// 使当前access_token失效，之后使用该access_token的请求返回42001
+func (s *Server) ExpireToken() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.token = ""
+}
+
+// gettoken被调用的次数
+func (s *Server) TokenRequests() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.tokenRequests
+}
+
+// 成功发送的应用消息的请求体
+func (s *Server) Messages() []json.RawMessage {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]json.RawMessage(nil), s.messages...)
+}
+
+// 成功上传的临时素材
+func (s *Server) Uploads() []Upload {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]Upload(nil), s.uploads...)
+}
+
+func writeJSON(rw http.ResponseWriter, v any) {
+	rw.Header().Set("content-type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
+
+func writeErrcode(rw http.ResponseWriter, errcode int) {
+	writeJSON(rw, map[string]any{"errcode": errcode, "errmsg": fmt.Sprintf("wecomtest: errcode %d", errcode)})
+}
+
+// 处理预设的失败，已写入响应时返回true
+func (s *Server) fail(rw http.ResponseWriter, r *http.Request) bool {
+	endpoint := strings.TrimPrefix(r.URL.Path, "/cgi-bin/")
+	s.lock.Lock()
+	fs := s.failures[endpoint]
+	if len(fs) == 0 {
+		s.lock.Unlock()
+		return false
+	}
+	f := fs[0]
+	s.failures[endpoint] = fs[1:]
+	s.lock.Unlock()
+
+	if f.status != 0 {
+		http.Error(rw, http.StatusText(f.status), f.status)
+	} else {
+		writeErrcode(rw, f.errcode)
+	}
+	return true
+}
+
+// 校验access_token，失败时已写入响应
+func (s *Server) authorize(rw http.ResponseWriter, r *http.Request) bool {
+	token := r.URL.Query().Get("access_token")
+	if token == "" {
+		writeErrcode(rw, 41001)
+		return false
+	}
+	s.lock.Lock()
+	valid := token == s.token
+	s.lock.Unlock()
+	if !valid {
+		writeErrcode(rw, 42001)
+		return false
+	}
+	return true
+}
+
+func (s *Server) gettoken(rw http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	s.tokenRequests++
+	s.lock.Unlock()
+	if s.fail(rw, r) {
+		return
+	}
+	q := r.URL.Query()
+	if q.Get("corpid") == "" || q.Get("corpsecret") == "" {
+		writeErrcode(rw, 40013)
+		return
+	}
+	s.lock.Lock()
+	s.token = fmt.Sprintf("wecomtest-token-%d", s.tokenRequests)
+	token := s.token
+	s.lock.Unlock()
+	writeJSON(rw, map[string]any{"errcode": 0, "errmsg": "ok", "access_token": token, "expires_in": 7200})
+}
+
+func (s *Server) messageSend(rw http.ResponseWriter, r *http.Request) {
+	if !s.authorize(rw, r) || s.fail(rw, r) {
+		return
+	}
+	b, err := io.ReadAll(r.Body)
+	if err != nil || !json.Valid(b) {
+		writeErrcode(rw, 47001)
+		return
+	}
+	s.lock.Lock()
+	s.messages = append(s.messages, b)
+	n := len(s.messages)
+	s.lock.Unlock()
+	writeJSON(rw, map[string]any{"errcode": 0, "errmsg": "ok", "msgid": fmt.Sprintf("wecomtest-msgid-%d", n)})
+}
+
+func (s *Server) mediaUpload(rw http.ResponseWriter, r *http.Request) {
+	if !s.authorize(rw, r) || s.fail(rw, r) {
+		return
+	}
+	f, h, err := r.FormFile("media")
+	if err != nil {
+		writeErrcode(rw, 41005)
+		return
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		writeErrcode(rw, 41005)
+		return
+	}
+	typ := r.URL.Query().Get("type")
+	s.lock.Lock()
+	s.uploads = append(s.uploads, Upload{Type: typ, Filename: h.Filename, Content: content})
+	n := len(s.uploads)
+	s.lock.Unlock()
+	writeJSON(rw, map[string]any{"errcode": 0, "errmsg": "ok", "type": typ, "media_id": fmt.Sprintf("wecomtest-media-%d", n), "created_at": "1380000000"})
+}
@@ -0,0 +1,176 @@
+// Package wecomzap 提供zapcore.Core，将高级别日志在后台推送到企业微信
+package wecomzap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/jzksnsjswkw/wecom-push"
+	"github.com/jzksnsjswkw/wecom-push/internal/logpush"
+)
+
+type Options struct {
+	// 推送的最低级别，默认为zapcore.ErrorLevel
+	Level zapcore.LevelEnabler
+	// 每分钟推送上限，默认10条
+	PerMinute int
+	// 等待推送的队列长度，默认为100，队列满时丢弃
+	QueueSize int
+	// Sync等待推送完成的最长时间，默认5秒，超时后返回错误，避免推送卡住时阻塞logger.Sync
+	SyncTimeout time.Duration
+}
+
+// 各Core共享的推送队列
+type sender struct {
+	n           wecom.Notifier
+	limiter     *logpush.Limiter
+	queue       chan string
+	syncTimeout time.Duration
+
+	lock    sync.Mutex
+	pending int
+	// 队列中的日志全部推送完成时关闭
+	idle chan struct{}
+}
+
+func (s *sender) run() {
+	for content := range s.queue {
+		_ = s.n.Notify(context.Background(), content)
+		s.lock.Lock()
+		s.pending--
+		if s.pending == 0 {
+			close(s.idle)
+		}
+		s.lock.Unlock()
+	}
+}
+
+// 返回是否加入队列及此前被丢弃的数量
+func (s *sender) reserve() (bool, int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.pending >= cap(s.queue) {
+		s.limiter.Drop()
+		return false, 0
+	}
+	ok, dropped := s.limiter.Allow()
+	if ok {
+		if s.pending == 0 {
+			s.idle = make(chan struct{})
+		}
+		s.pending++
+	}
+	return ok, dropped
+}
+
+type core struct {
+	zapcore.LevelEnabler
+	s      *sender
+	fields []zapcore.Field
+}
+
+// 与其他Core组合使用，如zapcore.NewTee(consoleCore, wecomzap.NewCore(bot, nil))
+// n可以是*wecom.Bot或客户端Notifier方法的返回值
+func NewCore(n wecom.Notifier, opts *Options) zapcore.Core {
+	if opts == nil {
+		opts = &Options{}
+	}
+	level := opts.Level
+	if level == nil {
+		level = zapcore.ErrorLevel
+	}
+	size := opts.QueueSize
+	if size <= 0 {
+		size = 100
+	}
+	syncTimeout := opts.SyncTimeout
+	if syncTimeout <= 0 {
+		syncTimeout = 5 * time.Second
+	}
+	s := &sender{
+		n:           n,
+		limiter:     logpush.NewLimiter(opts.PerMinute),
+		queue:       make(chan string, size),
+		syncTimeout: syncTimeout,
+	}
+	go s.run()
+	return &core{LevelEnabler: level, s: s}
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{
+		LevelEnabler: c.LevelEnabler,
+		s:            c.s,
+		fields:       append(append([]zapcore.Field(nil), c.fields...), fields...),
+	}
+}
+
+func (c *core) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+func (c *core) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	ok, dropped := c.s.reserve()
+	if !ok {
+		return nil
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<font color=\"warning\">**%s**</font> %s\n", e.Level.CapitalString(), e.Message)
+	fmt.Fprintf(&b, "> 时间：%s\n", e.Time.Format(time.DateTime))
+	if e.LoggerName != "" {
+		fmt.Fprintf(&b, "> logger：%s\n", e.LoggerName)
+	}
+	if e.Caller.Defined {
+		fmt.Fprintf(&b, "> caller：%s\n", e.Caller.TrimmedPath())
+	}
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "> %s：%v\n", k, enc.Fields[k])
+	}
+	c.s.queue <- logpush.Content(strings.TrimRight(b.String(), "\n"), dropped)
+	// DPanic、Panic及Fatal之后进程可能退出，等待推送完成
+	if e.Level > zapcore.ErrorLevel {
+		return c.Sync()
+	}
+	return nil
+}
+
+// 等待队列中的日志推送完成，logger.Sync时调用，最多等待Options.SyncTimeout
+func (c *core) Sync() error {
+	c.s.lock.Lock()
+	idle := c.s.idle
+	pending := c.s.pending
+	c.s.lock.Unlock()
+	if pending == 0 {
+		return nil
+	}
+	t := time.NewTimer(c.s.syncTimeout)
+	defer t.Stop()
+	select {
+	case <-idle:
+		return nil
+	case <-t.C:
+		return fmt.Errorf("wecomzap: sync timed out after %v", c.s.syncTimeout)
+	}
+}
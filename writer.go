@@ -0,0 +1,107 @@
+package wecom
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Writer 将写入的内容按行合并为文本消息发送，可用于log.New、io.MultiWriter等
+type Writer struct {
+	w       *Wecom
+	touser  string
+	agentID int
+	// 合并等待时间，期间写入的行合并为一条消息
+	delay time.Duration
+
+	lock  sync.Mutex
+	lines bytes.Buffer
+	// 尚未遇到换行符的部分
+	partial []byte
+	timer   *time.Timer
+}
+
+// 写入的完整行在1秒内合并为一条消息发送，超过2048字节时拆分为多条
+// 后台发送的错误通过WithLogger输出，使用完毕后需调用Close发送剩余内容
+func (w *Wecom) Writer(touser string, agentID int) *Writer {
+	return &Writer{w: w, touser: touser, agentID: agentID, delay: time.Second}
+}
+
+func (wr *Writer) Write(p []byte) (int, error) {
+	wr.lock.Lock()
+	defer wr.lock.Unlock()
+
+	data := append(wr.partial, p...)
+	i := bytes.LastIndexByte(data, '\n')
+	if i < 0 {
+		wr.partial = data
+		return len(p), nil
+	}
+	wr.lines.Write(data[:i+1])
+	wr.partial = append([]byte(nil), data[i+1:]...)
+
+	if wr.lines.Len() >= maxTextBytes {
+		return len(p), wr.flush()
+	}
+	if wr.timer == nil {
+		wr.timer = time.AfterFunc(wr.delay, func() {
+			wr.lock.Lock()
+			defer wr.lock.Unlock()
+			if err := wr.flush(); err != nil {
+				wr.w.logger.Printf("wecom: writer: %v", err)
+			}
+		})
+	}
+	return len(p), nil
+}
+
+// 立即发送已写入的完整行
+func (wr *Writer) Flush() error {
+	wr.lock.Lock()
+	defer wr.lock.Unlock()
+	return wr.flush()
+}
+
+// 发送剩余的全部内容，包括最后一行未换行的部分
+func (wr *Writer) Close() error {
+	wr.lock.Lock()
+	defer wr.lock.Unlock()
+	wr.lines.Write(wr.partial)
+	wr.partial = nil
+	return wr.flush()
+}
+
+func (wr *Writer) flush() error {
+	if wr.timer != nil {
+		wr.timer.Stop()
+		wr.timer = nil
+	}
+	data := bytes.TrimRight(wr.lines.Bytes(), "\n")
+	defer wr.lines.Reset()
+	for len(data) > 0 {
+		n := textChunk(string(data), maxTextBytes)
+		_, err := wr.w.Text(context.Background(), &TextInfo{
+			Touser:  wr.touser,
+			AgentID: wr.agentID,
+			Content: string(data[:n]),
+		})
+		if err != nil {
+			return err
+		}
+		data = bytes.TrimLeft(data[n:], "\n")
+	}
+	return nil
+}
+
+// 返回不超过max的长度，尽量在换行处拆分且不拆开UTF-8字符
+func textChunk(s string, max int) int {
+	if len(s) <= max {
+		return len(s)
+	}
+	if i := strings.LastIndexByte(s[:max], '\n'); i > 0 {
+		return i
+	}
+	return len(TruncateToBytes(s, max))
+}